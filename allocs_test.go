@@ -0,0 +1,53 @@
+//go:build !race
+
+package ygggo_log
+
+// 零分配测试：竞态检测会改变内存分配次数，因此在 -race 下不编译本文件
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestLogger_LogZeroAllocsBinary(t *testing.T) {
+	logger := NewLogger(io.Discard)
+	logger.SetFormatter(NewBinaryFormatter())
+	now := time.Now()
+	logFiveFields(logger, now) // 首条记录填充字符串表
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logFiveFields(logger, now)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected zero allocations once strings are interned, got: %v", allocs)
+	}
+}
+
+func TestLimits_ZeroAllocsWhenNotTruncated(t *testing.T) {
+	logger := NewLogger(io.Discard)
+	logger.SetLimits(Limits{MaxMessageSize: 1024, MaxFieldSize: 1024, MaxFields: 10, MaxEntrySize: 4096})
+	now := time.Now()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logFiveFields(logger, now)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected zero allocations, got: %v", allocs)
+	}
+}
+
+func TestLogger_LogZeroAllocs(t *testing.T) {
+	now := time.Now()
+	for _, f := range []Formatter{NewTextFormatter(), NewJsonFormatter()} {
+		logger := NewLogger(io.Discard)
+		logger.SetFormatter(f)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			logFiveFields(logger, now)
+		})
+		if allocs != 0 {
+			t.Errorf("Expected zero allocations with %T, got: %v", f, allocs)
+		}
+	}
+}
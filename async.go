@@ -1,36 +1,116 @@
 package ygggo_log
 
 import (
+	"context"
 	"io"
+	"sync"
 )
 
-// AsyncWriter 简单的异步写入器，使用channel缓冲
-// 注意：无关闭与flush机制，适合作为默认异步优化；需要严谨生命周期的场景可后续扩展
+// AsyncWriter 异步写入器：Write 将数据拷贝后放入 channel 立即返回，由后台 goroutine 依次写入底层 writer。
+// Flush 等待已写入的数据全部落到底层 writer，Close 写完队列中剩余的数据后停止后台 goroutine；
+// 底层 writer 返回的错误由 Flush 和 Close 报告。Close 之后的 Write 返回 ErrWriterClosed。
 type AsyncWriter struct {
-	w   io.Writer
-	ch  chan []byte
+	w      io.Writer
+	ch     chan asyncItem
+	done   chan struct{} // 后台 goroutine 退出时关闭
+	mu     sync.RWMutex  // 保护 closed，避免向已关闭的 channel 发送
+	closed bool
+	errMu  sync.Mutex
+	err    error // 上次 Flush 以来底层 writer 返回的第一个错误
 }
 
+// asyncItem 队列中的一项：待写入的数据，或 Flush 的标记
+type asyncItem struct {
+	p       []byte
+	flushed chan struct{} // 非 nil 时表示 Flush 标记，处理到这里时关闭
+}
+
+// NewAsyncWriter 创建异步写入器，bufSize 为队列中最多缓存的写入次数，队列满时 Write 阻塞
 func NewAsyncWriter(w io.Writer, bufSize int) *AsyncWriter {
 	aw := &AsyncWriter{
-		w:  w,
-		ch: make(chan []byte, bufSize),
+		w:    w,
+		ch:   make(chan asyncItem, bufSize),
+		done: make(chan struct{}),
 	}
 	go aw.loop()
 	return aw
 }
 
+// Write 拷贝 p 后放入队列；写入器已关闭时返回 ErrWriterClosed
 func (aw *AsyncWriter) Write(p []byte) (int, error) {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+	if aw.closed {
+		return 0, ErrWriterClosed
+	}
 	// 拷贝避免调用方复用切片带来的数据竞争
 	cp := make([]byte, len(p))
 	copy(cp, p)
-	aw.ch <- cp
+	aw.ch <- asyncItem{p: cp}
 	return len(p), nil
 }
 
+// Flush 等待调用前写入的数据全部交给底层 writer，返回期间发生的第一个写入错误；
+// ctx 结束时返回 ctx.Err()，队列中的数据仍会继续写入
+func (aw *AsyncWriter) Flush(ctx context.Context) error {
+	aw.mu.RLock()
+	if aw.closed {
+		aw.mu.RUnlock()
+		return aw.takeErr()
+	}
+	flushed := make(chan struct{})
+	select {
+	case aw.ch <- asyncItem{flushed: flushed}:
+		aw.mu.RUnlock()
+	case <-ctx.Done():
+		aw.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return aw.takeErr()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 停止接收新数据，写完队列中剩余的数据后停止后台 goroutine，返回尚未报告的第一个写入错误。
+// 底层 writer 不会被关闭；重复调用 Close 是安全的
+func (aw *AsyncWriter) Close() error {
+	aw.mu.Lock()
+	if !aw.closed {
+		aw.closed = true
+		close(aw.ch)
+	}
+	aw.mu.Unlock()
+	<-aw.done
+	return aw.takeErr()
+}
+
+// loop 依次写入队列中的数据，直到 channel 关闭
 func (aw *AsyncWriter) loop() {
-	for b := range aw.ch {
-		_, _ = aw.w.Write(b)
+	defer close(aw.done)
+	for item := range aw.ch {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		if _, err := aw.w.Write(item.p); err != nil {
+			aw.errMu.Lock()
+			if aw.err == nil {
+				aw.err = err
+			}
+			aw.errMu.Unlock()
+		}
 	}
 }
 
+// takeErr 返回并清除记录的写入错误
+func (aw *AsyncWriter) takeErr() error {
+	aw.errMu.Lock()
+	defer aw.errMu.Unlock()
+	err := aw.err
+	aw.err = nil
+	return err
+}
@@ -0,0 +1,159 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowWriter 每次写入前等待，用于验证 Flush 和 Close 会等待队列写完
+type slowWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	delay time.Duration
+	err   error
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriter_Flush(t *testing.T) {
+	out := &slowWriter{delay: time.Millisecond}
+	aw := NewAsyncWriter(out, 16)
+	defer aw.Close()
+
+	for i := 0; i < 10; i++ {
+		aw.Write([]byte("line\n"))
+	}
+	if err := aw.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if got := strings.Count(out.String(), "line\n"); got != 10 {
+		t.Errorf("Expected 10 lines after Flush, got: %d", got)
+	}
+}
+
+func TestAsyncWriter_FlushContext(t *testing.T) {
+	out := &slowWriter{delay: 50 * time.Millisecond}
+	aw := NewAsyncWriter(out, 16)
+	defer aw.Close()
+
+	aw.Write([]byte("slow\n"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := aw.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func TestAsyncWriter_CloseDrains(t *testing.T) {
+	out := &slowWriter{delay: time.Millisecond}
+	aw := NewAsyncWriter(out, 64)
+
+	for i := 0; i < 20; i++ {
+		aw.Write([]byte("queued\n"))
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := strings.Count(out.String(), "queued\n"); got != 20 {
+		t.Errorf("Expected all 20 queued lines after Close, got: %d", got)
+	}
+	if err := aw.Close(); err != nil {
+		t.Errorf("Expected a second Close to succeed, got: %v", err)
+	}
+}
+
+func TestAsyncWriter_WriteAfterClose(t *testing.T) {
+	aw := NewAsyncWriter(&slowWriter{}, 1)
+	aw.Close()
+
+	if _, err := aw.Write([]byte("late\n")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed, got: %v", err)
+	}
+	if err := aw.Flush(context.Background()); err != nil {
+		t.Errorf("Expected Flush after Close to return nil, got: %v", err)
+	}
+}
+
+func TestAsyncWriter_ConcurrentWriteAndClose(t *testing.T) {
+	out := &slowWriter{}
+	aw := NewAsyncWriter(out, 4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := aw.Write([]byte("x\n")); err != nil {
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	aw.Close() // 不应因向已关闭的 channel 发送而 panic
+	wg.Wait()
+}
+
+func TestAsyncWriter_ErrorPropagation(t *testing.T) {
+	out := &slowWriter{err: errors.New("broken pipe")}
+	aw := NewAsyncWriter(out, 4)
+
+	aw.Write([]byte("lost\n"))
+	if err := aw.Flush(context.Background()); err == nil || err.Error() != "broken pipe" {
+		t.Errorf("Expected the write error from Flush, got: %v", err)
+	}
+	if err := aw.Flush(context.Background()); err != nil {
+		t.Errorf("Expected the error to be reported once, got: %v", err)
+	}
+	aw.Write([]byte("lost again\n"))
+	if err := aw.Close(); err == nil || err.Error() != "broken pipe" {
+		t.Errorf("Expected the write error from Close, got: %v", err)
+	}
+}
+
+func TestLogger_CloseDrainsCombinedOutputs(t *testing.T) {
+	console := &slowWriter{delay: time.Millisecond}
+	file := filepath.Join(t.TempDir(), "app.log")
+	rw, err := NewRotatingWriter(file, 0, 3)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	bw := NewBufferedWriter(rw, 0)
+	logger := NewLogger(nil)
+	logger.SetFormatter(NewCombinedFormatterWith(NewAsyncWriter(console, 16), NewTextFormatter(), bw, NewJsonFormatter()))
+
+	for i := 0; i < 5; i++ {
+		logger.Info("shutdown")
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := strings.Count(console.String(), "shutdown"); got != 5 {
+		t.Errorf("Expected 5 console lines, got: %d", got)
+	}
+	if data, _ := os.ReadFile(file); strings.Count(string(data), "shutdown") != 5 {
+		t.Errorf("Expected 5 file lines, got: %q", data)
+	}
+}
@@ -0,0 +1,169 @@
+package ygggo_log
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// BinaryMagic 二进制日志流的起始标记，同时表示字符串表重置。
+// 记录长度不会为 0，因此以 0 字节开头的标记不会与记录混淆。最后一个字节为格式版本
+const BinaryMagic = "\x00YGLB\x02"
+
+// maxInternedStrings 字符串表的容量上限，超过后重置字符串表
+const maxInternedStrings = 4096
+
+// BinaryFormatter 输出紧凑的二进制日志，比 JSON 行更小、编码更快，需要用 binlog 包解码。
+//
+// 日志流由 BinaryMagic 开头，之后是一条条以 uvarint 长度为前缀的记录：
+//
+//	时间    与上一条记录的 Unix 秒差（zigzag varint，BinaryMagic 之后的第一条为 Unix 秒）+ 纳秒（uvarint）
+//	级别    1 字节
+//	消息    uvarint 长度 + UTF-8 字节
+//	调用位置 文件（字符串引用）、行号（uvarint）、函数（字符串引用）
+//	参数    uvarint 个数，每个参数为 键（字符串引用）、类型（1 字节，FieldType 的值）、值
+//
+// 字符串引用用于键名和调用位置：0 表示后面紧跟 uvarint 长度和内容并加入字符串表，
+// n > 0 表示字符串表中的第 n 个字符串。值按类型编码：整数和时长为 zigzag varint，
+// 无符号整数为 uvarint，浮点数为 8 字节小端 IEEE 754，布尔为 1 字节，时间为 Unix 秒 varint + 纳秒 uvarint，
+// 字符串和错误为 uvarint 长度 + 内容，其他类型为 JSON（无法编码时按 %v 输出为字符串）。
+//
+// BinaryMagic 可以出现在任意记录边界并重置字符串表和时间基准：每个 BinaryFormatter 的首条记录、
+// 字符串表写满时以及写入 RotatingWriter 的新文件时都会输出它，因此每个文件都可以单独解码。
+// BinaryFormatter 会串行化自己的写入，同一个输出不应再与其他格式化器共享。
+type BinaryFormatter struct {
+	mu      sync.Mutex
+	strings map[string]uint64         // 字符串表，nil 表示下一条记录需要先输出 BinaryMagic
+	lastSec int64                     // 上一条记录的 Unix 秒
+	frames  map[uintptr]runtime.Frame // 已解析的调用位置，避免重复解析程序计数器
+}
+
+// NewBinaryFormatter 创建二进制格式化器；时间以纳秒精度保存，时间配置不生效
+func NewBinaryFormatter(opts ...FormatterOption) *BinaryFormatter {
+	return &BinaryFormatter{}
+}
+
+// Format 格式化为二进制记录
+func (f *BinaryFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为二进制记录
+func (f *BinaryFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	buf := getBuffer()
+	buf.b = f.appendRecord(buf.b, e)
+	if rw, ok := writer.(interface{ WillRotate(n int) bool }); ok && buf.b[0] != 0 && rw.WillRotate(len(buf.b)) {
+		// 记录将写入新文件：重置字符串表后重新编码，使新文件可以单独解码
+		f.strings = nil
+		buf.b = f.appendRecord(buf.b[:0], e)
+	}
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendRecord 追加一条以长度为前缀的记录，需要时先追加 BinaryMagic
+func (f *BinaryFormatter) appendRecord(dst []byte, e *Entry) []byte {
+	if f.strings == nil || len(f.strings) >= maxInternedStrings {
+		f.strings = make(map[string]uint64)
+		f.lastSec = 0
+		dst = append(dst, BinaryMagic...)
+	}
+
+	body := getBuffer()
+	// 秒差按 int64 回绕计算，解码时同样回绕相加，任意年份的时间都能还原
+	sec := e.Time.Unix()
+	body.b = binary.AppendVarint(body.b, sec-f.lastSec)
+	body.b = binary.AppendUvarint(body.b, uint64(e.Time.Nanosecond()))
+	f.lastSec = sec
+	body.b = append(body.b, byte(e.Level))
+	body.b = appendBinaryString(body.b, e.Message)
+
+	frame := f.callerFrame(e)
+	body.b = f.appendStringRef(body.b, frame.File)
+	body.b = binary.AppendUvarint(body.b, uint64(frame.Line))
+	body.b = f.appendStringRef(body.b, frame.Function)
+
+	body.b = binary.AppendUvarint(body.b, uint64(len(e.Fields)))
+	for _, field := range e.Fields {
+		body.b = f.appendStringRef(body.b, field.Key)
+		body.b = appendBinaryValue(body.b, field)
+	}
+
+	dst = binary.AppendUvarint(dst, uint64(len(body.b)))
+	dst = append(dst, body.b...)
+	putBuffer(body)
+	return dst
+}
+
+// callerFrame 返回调用位置，按程序计数器缓存解析结果
+func (f *BinaryFormatter) callerFrame(e *Entry) runtime.Frame {
+	if e.pc == 0 {
+		return e.CallerFrame()
+	}
+	if frame, ok := f.frames[e.pc]; ok {
+		return frame
+	}
+	if f.frames == nil || len(f.frames) >= maxInternedStrings {
+		f.frames = make(map[uintptr]runtime.Frame)
+	}
+	frame := e.CallerFrame()
+	f.frames[e.pc] = frame
+	return frame
+}
+
+// appendStringRef 追加字符串引用，首次出现的字符串内联输出并加入字符串表
+func (f *BinaryFormatter) appendStringRef(dst []byte, s string) []byte {
+	if id, ok := f.strings[s]; ok {
+		return binary.AppendUvarint(dst, id)
+	}
+	f.strings[s] = uint64(len(f.strings) + 1)
+	dst = append(dst, 0)
+	return appendBinaryString(dst, s)
+}
+
+// appendBinaryString 追加 uvarint 长度和字符串内容
+func appendBinaryString(dst []byte, s string) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(s)))
+	return append(dst, s...)
+}
+
+// appendBinaryValue 追加类型字节和参数值
+func appendBinaryValue(dst []byte, f Field) []byte {
+	switch f.Type {
+	case StringType:
+		dst = append(dst, byte(StringType))
+		return appendBinaryString(dst, f.str)
+	case IntType, DurationType:
+		dst = append(dst, byte(f.Type))
+		return binary.AppendVarint(dst, f.num)
+	case UintType:
+		dst = append(dst, byte(UintType))
+		return binary.AppendUvarint(dst, uint64(f.num))
+	case FloatType:
+		dst = append(dst, byte(FloatType))
+		return binary.LittleEndian.AppendUint64(dst, uint64(f.num))
+	case BoolType:
+		return append(dst, byte(BoolType), byte(f.num))
+	case TimeType:
+		dst = append(dst, byte(TimeType))
+		dst = binary.AppendVarint(dst, f.num)
+		return binary.AppendUvarint(dst, uint64(f.nsec))
+	case ErrorType:
+		dst = append(dst, byte(ErrorType))
+		return appendBinaryString(dst, f.String())
+	default:
+		if data, err := json.Marshal(f.iface); err == nil {
+			dst = append(dst, byte(AnyType))
+			dst = binary.AppendUvarint(dst, uint64(len(data)))
+			return append(dst, data...)
+		}
+		dst = append(dst, byte(StringType))
+		return appendBinaryString(dst, fmt.Sprint(f.iface))
+	}
+}
@@ -0,0 +1,85 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBinaryFormatter_Header(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewBinaryFormatter())
+
+	logger.Info("first", String("user", "tom"))
+	firstLen := buf.Len()
+	logger.Info("first", String("user", "tom"))
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte(BinaryMagic)) {
+		t.Fatalf("Expected stream to start with the magic header, got: %q", buf.Bytes())
+	}
+	if bytes.Count(buf.Bytes(), []byte(BinaryMagic)) != 1 {
+		t.Errorf("Expected a single header for one stream")
+	}
+	second := buf.Len() - firstLen
+	if second >= firstLen-len(BinaryMagic) {
+		t.Errorf("Expected interned strings to shrink the second record: %d vs %d bytes", second, firstLen)
+	}
+}
+
+func TestBinaryFormatter_SmallerThanJSON(t *testing.T) {
+	var bin, js bytes.Buffer
+	binLogger := NewLogger(&bin)
+	binLogger.SetFormatter(NewBinaryFormatter())
+	jsonLogger := NewLogger(&js)
+	jsonLogger.SetFormatter(NewJsonFormatter(WithInlineFields()))
+
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		logFiveFields(binLogger, now)
+		logFiveFields(jsonLogger, now)
+	}
+	if bin.Len()*2 > js.Len() {
+		t.Errorf("Expected binary output to be less than half of JSON: %d vs %d bytes", bin.Len(), js.Len())
+	}
+}
+
+func TestBinaryFormatter_HeaderOnRotation(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.bin")
+	rw, err := NewRotatingWriter(filename, 200, 3)
+	if err != nil {
+		t.Fatalf("Failed to create rotating writer: %v", err)
+	}
+	defer rw.Close()
+
+	logger := NewLogger(rw)
+	logger.SetFormatter(NewBinaryFormatter())
+	for i := 0; i < 10; i++ {
+		logger.Info("rotation test", String("user", "tom"), Int("i", i))
+	}
+
+	for _, name := range []string{filename, filename + ".1"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if !bytes.HasPrefix(data, []byte(BinaryMagic)) {
+			t.Errorf("Expected %s to start with the magic header", filepath.Base(name))
+		}
+	}
+}
+
+func BenchmarkBinaryFormatter_FiveFields(b *testing.B) {
+	logger := NewLogger(io.Discard)
+	logger.SetFormatter(NewBinaryFormatter())
+	now := time.Now()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logFiveFields(logger, now)
+	}
+}
@@ -0,0 +1,290 @@
+// Package binlog 解码 ygggo_log.BinaryFormatter 写出的二进制日志，
+// 并可以用任意内置格式化器把记录重新输出为文本或 JSON：
+//
+//	f, _ := os.Open("logs/app.log")
+//	defer f.Close()
+//	err := binlog.Convert(os.Stdout, f, ygggo_log.NewJsonFormatter())
+package binlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/yggai/ygggo_log"
+)
+
+// ErrCorrupt 表示数据不符合二进制日志格式
+var ErrCorrupt = errors.New("binlog: corrupt binary log")
+
+// maxRecordSize 单条记录的长度上限，防止损坏的长度前缀导致超大内存分配
+const maxRecordSize = 64 << 20
+
+// Record 一条解码后的日志记录
+type Record struct {
+	Time     time.Time
+	Level    ygggo_log.LogLevel
+	Message  string
+	File     string // 调用位置的源文件完整路径，未知时为空
+	Line     int
+	Function string
+	Fields   []ygggo_log.Field
+}
+
+// Entry 将记录转换为 ygggo_log.Entry，供格式化器的 FormatEntry 使用
+func (r *Record) Entry() *ygggo_log.Entry {
+	e := &ygggo_log.Entry{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Fields:  r.Fields,
+	}
+	e.SetCaller(r.File, r.Line, r.Function)
+	return e
+}
+
+// RawJSON 以 JSON 保存的参数值（写入时为 AnyType 的值）。
+// JSON 格式化器原样输出，文本格式化器输出 JSON 文本
+type RawJSON []byte
+
+// MarshalJSON 原样返回 JSON
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	return j, nil
+}
+
+// String 返回 JSON 文本
+func (j RawJSON) String() string {
+	return string(j)
+}
+
+// Reader 从二进制日志流中逐条读取记录
+type Reader struct {
+	r       *bufio.Reader
+	strings []string // 字符串表，下标 0 对应引用 1
+	lastSec int64    // 上一条记录的 Unix 秒
+	started bool     // 是否已读到 BinaryMagic
+	buf     []byte
+}
+
+// NewReader 创建读取器
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next 读取下一条记录，数据结束时返回 io.EOF；记录不完整或格式错误时返回 ErrCorrupt
+func (r *Reader) Next() (*Record, error) {
+	for {
+		c, err := r.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			if err := r.readMagic(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !r.started {
+			return nil, fmt.Errorf("%w: missing header", ErrCorrupt)
+		}
+		r.r.UnreadByte()
+		size, err := binary.ReadUvarint(r.r)
+		if err != nil || size > maxRecordSize {
+			return nil, corrupt(err)
+		}
+		if uint64(cap(r.buf)) < size {
+			r.buf = make([]byte, size)
+		}
+		r.buf = r.buf[:size]
+		if _, err := io.ReadFull(r.r, r.buf); err != nil {
+			return nil, corrupt(err)
+		}
+		rec, err := r.decode(r.buf)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		return rec, nil
+	}
+}
+
+// readMagic 读取 BinaryMagic 的剩余部分并重置字符串表和时间基准
+func (r *Reader) readMagic() error {
+	magic := ygggo_log.BinaryMagic
+	rest := make([]byte, len(magic)-1)
+	if _, err := io.ReadFull(r.r, rest); err != nil || string(rest[:len(rest)-1]) != magic[1:len(magic)-1] {
+		return fmt.Errorf("%w: bad header", ErrCorrupt)
+	}
+	if version := rest[len(rest)-1]; version != magic[len(magic)-1] {
+		return fmt.Errorf("%w: unsupported version %d", ErrCorrupt, version)
+	}
+	r.strings = r.strings[:0]
+	r.lastSec = 0
+	r.started = true
+	return nil
+}
+
+// corrupt 将读取错误包装为 ErrCorrupt
+func corrupt(err error) error {
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: truncated record", ErrCorrupt)
+	}
+	if errors.Is(err, ErrCorrupt) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrCorrupt, err)
+}
+
+// decoder 在一条记录的字节中顺序读取
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = ErrCorrupt
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.err = ErrCorrupt
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) bytes(n uint64) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if uint64(len(d.b)) < n {
+		d.err = ErrCorrupt
+		return nil
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) byte() byte {
+	if v := d.bytes(1); v != nil {
+		return v[0]
+	}
+	return 0
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes(d.uvarint()))
+}
+
+// stringRef 读取字符串引用，内联的字符串加入字符串表
+func (r *Reader) stringRef(d *decoder) string {
+	id := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if id == 0 {
+		s := d.string()
+		r.strings = append(r.strings, s)
+		return s
+	}
+	if id > uint64(len(r.strings)) {
+		d.err = fmt.Errorf("%w: unknown string reference %d", ErrCorrupt, id)
+		return ""
+	}
+	return r.strings[id-1]
+}
+
+// decode 解码一条记录
+func (r *Reader) decode(b []byte) (*Record, error) {
+	d := &decoder{b: b}
+	rec := &Record{}
+
+	r.lastSec += d.varint()
+	rec.Time = time.Unix(r.lastSec, int64(d.uvarint()))
+	rec.Level = ygggo_log.LogLevel(d.byte())
+	rec.Message = d.string()
+	rec.File = r.stringRef(d)
+	rec.Line = int(d.uvarint())
+	rec.Function = r.stringRef(d)
+
+	n := d.uvarint()
+	if n > uint64(len(d.b)) {
+		return nil, ErrCorrupt
+	}
+	rec.Fields = make([]ygggo_log.Field, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		key := r.stringRef(d)
+		rec.Fields = append(rec.Fields, decodeValue(d, key))
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return rec, nil
+}
+
+// decodeValue 按类型字节解码参数值
+func decodeValue(d *decoder, key string) ygggo_log.Field {
+	switch t := ygggo_log.FieldType(d.byte()); t {
+	case ygggo_log.StringType:
+		return ygggo_log.String(key, d.string())
+	case ygggo_log.IntType:
+		return ygggo_log.Int64(key, d.varint())
+	case ygggo_log.DurationType:
+		return ygggo_log.Duration(key, time.Duration(d.varint()))
+	case ygggo_log.UintType:
+		return ygggo_log.Uint64(key, d.uvarint())
+	case ygggo_log.FloatType:
+		v := d.bytes(8)
+		if v == nil {
+			return ygggo_log.Field{}
+		}
+		return ygggo_log.Float64(key, math.Float64frombits(binary.LittleEndian.Uint64(v)))
+	case ygggo_log.BoolType:
+		return ygggo_log.Bool(key, d.byte() == 1)
+	case ygggo_log.TimeType:
+		sec := d.varint()
+		return ygggo_log.Time(key, time.Unix(sec, int64(d.uvarint())))
+	case ygggo_log.ErrorType:
+		return ygggo_log.Any(key, errors.New(d.string()))
+	case ygggo_log.AnyType:
+		return ygggo_log.Any(key, append(RawJSON(nil), d.bytes(d.uvarint())...))
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("%w: unknown field type %d", ErrCorrupt, t)
+		}
+		return ygggo_log.Field{}
+	}
+}
+
+// Convert 读取 src 中的全部记录，用格式化器 f 依次输出到 dst
+func Convert(dst io.Writer, src io.Reader, f ygggo_log.EntryFormatter) error {
+	r := NewReader(src)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f.FormatEntry(dst, rec.Entry())
+	}
+}
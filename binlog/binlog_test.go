@@ -0,0 +1,268 @@
+package binlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yggai/ygggo_log"
+)
+
+var testTime = time.Date(2025, 3, 1, 12, 30, 45, 123456789, time.UTC)
+
+// encode 用 BinaryFormatter 写出若干条记录
+func encode(f *ygggo_log.BinaryFormatter, w io.Writer, entries ...*ygggo_log.Entry) {
+	for _, e := range entries {
+		f.FormatEntry(w, e)
+	}
+}
+
+func sampleEntry(msg string, offset time.Duration, fields ...ygggo_log.Field) *ygggo_log.Entry {
+	e := &ygggo_log.Entry{
+		Time:    testTime.Add(offset),
+		Level:   ygggo_log.WarningLevel,
+		Message: msg,
+		Fields:  fields,
+	}
+	e.SetCaller("/src/app/main.go", 42, "main.handle")
+	return e
+}
+
+func TestReader_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	fields := []ygggo_log.Field{
+		ygggo_log.String("user", "tom"),
+		ygggo_log.Int("count", -3),
+		ygggo_log.Uint64("big", math.MaxUint64),
+		ygggo_log.Float64("ratio", 0.25),
+		ygggo_log.Bool("ok", true),
+		ygggo_log.Duration("took", 1500*time.Millisecond),
+		ygggo_log.Time("at", at),
+		ygggo_log.Err(errors.New("boom")),
+		ygggo_log.Any("tags", []string{"a", "b"}),
+	}
+	encode(ygggo_log.NewBinaryFormatter(), &buf,
+		sampleEntry("first", 0, fields...),
+		sampleEntry("second", -time.Second, fields...),
+	)
+
+	r := NewReader(&buf)
+	for i, want := range []struct {
+		msg    string
+		offset time.Duration
+	}{{"first", 0}, {"second", -time.Second}} {
+		rec, err := r.Next()
+		if err != nil {
+			t.Fatalf("Record %d: unexpected error: %v", i, err)
+		}
+		if rec.Message != want.msg || !rec.Time.Equal(testTime.Add(want.offset)) || rec.Level != ygggo_log.WarningLevel {
+			t.Errorf("Record %d: unexpected header: %+v", i, rec)
+		}
+		if rec.File != "/src/app/main.go" || rec.Line != 42 || rec.Function != "main.handle" {
+			t.Errorf("Record %d: unexpected caller: %s:%d %s", i, rec.File, rec.Line, rec.Function)
+		}
+		if len(rec.Fields) != len(fields) {
+			t.Fatalf("Record %d: expected %d fields, got: %d", i, len(fields), len(rec.Fields))
+		}
+		for j, f := range fields[:len(fields)-1] {
+			got := rec.Fields[j]
+			if got.Key != f.Key || got.String() != f.String() {
+				t.Errorf("Record %d: field %d expected %s=%s, got: %s=%s", i, j, f.Key, f.String(), got.Key, got.String())
+			}
+		}
+		if tags := rec.Fields[len(fields)-1]; tags.Key != "tags" || tags.String() != `["a","b"]` {
+			t.Errorf("Record %d: expected Any values as JSON, got: %s=%s", i, tags.Key, tags.String())
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF at the end, got: %v", err)
+	}
+}
+
+func TestReader_TimeFieldsOutsideUnixNanoRange(t *testing.T) {
+	var buf bytes.Buffer
+	times := []time.Time{{}, time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC)}
+	encode(ygggo_log.NewBinaryFormatter(), &buf,
+		sampleEntry("sentinels", 0, ygggo_log.Time("zero", times[0]), ygggo_log.Time("far", times[1])))
+
+	rec, err := NewReader(&buf).Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, want := range times {
+		if got, _ := rec.Fields[i].Value().(time.Time); !got.Equal(want) {
+			t.Errorf("Field %s: expected %v, got: %v", rec.Fields[i].Key, want, got)
+		}
+	}
+}
+
+func TestReader_RecordTimesOutsideUnixNanoRange(t *testing.T) {
+	var buf bytes.Buffer
+	times := []time.Time{{}, time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC), testTime}
+	f := ygggo_log.NewBinaryFormatter()
+	for _, at := range times {
+		e := sampleEntry("at", 0)
+		e.Time = at
+		encode(f, &buf, e)
+	}
+
+	r := NewReader(&buf)
+	for i, want := range times {
+		rec, err := r.Next()
+		if err != nil {
+			t.Fatalf("Record %d: unexpected error: %v", i, err)
+		}
+		if !rec.Time.Equal(want) {
+			t.Errorf("Record %d: expected %v, got: %v", i, want, rec.Time)
+		}
+	}
+}
+
+func TestReader_UnsupportedVersion(t *testing.T) {
+	stream := []byte("\x00YGLB\x01\x01\x00")
+	if _, err := NewReader(bytes.NewReader(stream)).Next(); !errors.Is(err, ErrCorrupt) || !strings.Contains(err.Error(), "unsupported version 1") {
+		t.Errorf("Expected an unsupported version error, got: %v", err)
+	}
+}
+
+func TestConvert_Text(t *testing.T) {
+	var bin, out bytes.Buffer
+	encode(ygggo_log.NewBinaryFormatter(), &bin, sampleEntry("hello", 0, ygggo_log.String("user", "tom")))
+
+	if err := Convert(&out, &bin, ygggo_log.NewTextFormatter(ygggo_log.WithTimeLocation(time.UTC))); err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	line := out.String()
+	for _, want := range []string{"2025-03-01", "WARNING", "hello", "user=tom"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected output to contain %q, got: %q", want, line)
+		}
+	}
+}
+
+func TestConvert_PatternCaller(t *testing.T) {
+	var bin, out bytes.Buffer
+	encode(ygggo_log.NewBinaryFormatter(), &bin, sampleEntry("hello", 0))
+
+	pf, err := ygggo_log.NewPatternFormatter("%caller %msg")
+	if err != nil {
+		t.Fatalf("Failed to create pattern formatter: %v", err)
+	}
+	if err := Convert(&out, &bin, pf); err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if out.String() != "main.go:42 hello\n" {
+		t.Errorf("Expected the recorded caller, got: %q", out.String())
+	}
+}
+
+func TestConvert_JSON(t *testing.T) {
+	var bin, out bytes.Buffer
+	encode(ygggo_log.NewBinaryFormatter(), &bin,
+		sampleEntry("hello", 0, ygggo_log.Int("status", 200), ygggo_log.Any("tags", []string{"a"})))
+
+	if err := Convert(&out, &bin, ygggo_log.NewJsonFormatter(ygggo_log.WithInlineFields())); err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	line := out.String()
+	for _, want := range []string{`"message":"hello"`, `"status":200`, `"tags":["a"]`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected output to contain %s, got: %s", want, line)
+		}
+	}
+}
+
+func TestReader_Corrupt(t *testing.T) {
+	var buf bytes.Buffer
+	encode(ygggo_log.NewBinaryFormatter(), &buf, sampleEntry("hello", 0, ygggo_log.String("user", "tom")))
+	data := buf.Bytes()
+
+	cases := map[string][]byte{
+		"missing header": data[len(ygggo_log.BinaryMagic):],
+		"bad header":     append([]byte("\x00XXXX\x01"), data[len(ygggo_log.BinaryMagic):]...),
+		"truncated":      data[:len(data)-3],
+	}
+	for name, input := range cases {
+		_, err := NewReader(bytes.NewReader(input)).Next()
+		if !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: expected ErrCorrupt, got: %v", name, err)
+		}
+	}
+
+	if _, err := NewReader(bytes.NewReader(nil)).Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF for empty input, got: %v", err)
+	}
+}
+
+func TestReader_RestartedWriter(t *testing.T) {
+	// 进程重启后以新的 BinaryFormatter 追加到同一文件：流中间出现的 BinaryMagic 重置字符串表
+	var buf bytes.Buffer
+	encode(ygggo_log.NewBinaryFormatter(), &buf, sampleEntry("before", 0, ygggo_log.String("user", "tom")))
+	encode(ygggo_log.NewBinaryFormatter(), &buf, sampleEntry("after", time.Minute, ygggo_log.String("id", "7")))
+
+	r := NewReader(&buf)
+	var got []string
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, rec.Message+":"+rec.Fields[0].Key+"="+rec.Fields[0].String())
+	}
+	if strings.Join(got, ",") != "before:user=tom,after:id=7" {
+		t.Errorf("Unexpected records: %v", got)
+	}
+}
+
+func TestReader_RotatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.bin")
+	rw, err := ygggo_log.NewRotatingWriter(filename, 256, 10)
+	if err != nil {
+		t.Fatalf("Failed to create rotating writer: %v", err)
+	}
+	f := ygggo_log.NewBinaryFormatter()
+	for i := 0; i < 20; i++ {
+		encode(f, rw, sampleEntry("rotation", time.Duration(i)*time.Second, ygggo_log.Int("i", i)))
+	}
+	rw.Close()
+
+	files, _ := filepath.Glob(filename + "*")
+	if len(files) < 2 {
+		t.Fatalf("Expected rotation to produce several files, got: %v", files)
+	}
+	total := 0
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		r := NewReader(bytes.NewReader(data))
+		for {
+			rec, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", filepath.Base(name), err)
+			}
+			if rec.File != "/src/app/main.go" || rec.Fields[0].Key != "i" {
+				t.Errorf("%s: record not decoded independently: %+v", filepath.Base(name), rec)
+			}
+			total++
+		}
+	}
+	if total != 20 {
+		t.Errorf("Expected 20 records across files, got: %d", total)
+	}
+}
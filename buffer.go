@@ -0,0 +1,215 @@
+package ygggo_log
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// buffer 是格式化器使用的可复用字节缓冲区
+type buffer struct {
+	b []byte
+}
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return &buffer{b: make([]byte, 0, 1024)}
+	},
+}
+
+// maxPooledBuffer 超过该容量的缓冲区直接丢弃，避免一次超大日志让池子长期持有大块内存
+const maxPooledBuffer = 64 * 1024
+
+// getBuffer 从对象池获取一个空缓冲区
+func getBuffer() *buffer {
+	buf := bufferPool.Get().(*buffer)
+	buf.b = buf.b[:0]
+	return buf
+}
+
+// putBuffer 将缓冲区归还对象池
+func putBuffer(buf *buffer) {
+	if cap(buf.b) > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// stringAppender 将字符串追加到 dst，可在追加的同时完成转义
+type stringAppender func(dst []byte, s string) []byte
+
+// appendRaw 原样追加字符串
+func appendRaw(dst []byte, s string) []byte {
+	return append(dst, s...)
+}
+
+// appendFieldValue 以 %v 的文本形式追加 Field 的值
+func appendFieldValue(dst []byte, f Field) []byte {
+	return appendFieldValueFunc(dst, f, appendRaw)
+}
+
+// appendFieldValueFunc 以 %v 的文本形式追加 Field 的值，字符串部分交给 str 处理。
+// 除 AnyType 和 DurationType 外均不产生堆分配。
+func appendFieldValueFunc(dst []byte, f Field, str stringAppender) []byte {
+	switch f.Type {
+	case StringType:
+		return str(dst, f.str)
+	case IntType:
+		return strconv.AppendInt(dst, f.num, 10)
+	case UintType:
+		return strconv.AppendUint(dst, uint64(f.num), 10)
+	case FloatType:
+		return strconv.AppendFloat(dst, f.float(), 'g', -1, 64)
+	case BoolType:
+		return strconv.AppendBool(dst, f.num == 1)
+	case DurationType:
+		return str(dst, time.Duration(f.num).String())
+	case TimeType:
+		return f.time().AppendFormat(dst, time.RFC3339Nano)
+	case ErrorType:
+		if err, ok := f.iface.(error); ok && err != nil {
+			return str(dst, err.Error())
+		}
+		return str(dst, "<nil>")
+	default:
+		return str(dst, fmt.Sprint(f.iface))
+	}
+}
+
+// appendTextFields 以空格分隔的 key=value 形式追加参数，Key 为空的参数只输出值
+func appendTextFields(dst []byte, fields []Field, str stringAppender) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		if f.Key != "" {
+			dst = str(dst, f.Key)
+			dst = append(dst, '=')
+		}
+		dst = appendFieldValueFunc(dst, f, str)
+	}
+	return dst
+}
+
+// appendMessageText 追加消息正文和参数，两者之间用一个空格分隔
+func appendMessageText(dst []byte, e *Entry, str stringAppender) []byte {
+	dst = str(dst, e.Message)
+	if len(e.Fields) > 0 {
+		dst = append(dst, ' ')
+		dst = appendTextFields(dst, e.Fields, str)
+	}
+	return dst
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString 追加一个带双引号的 JSON 字符串
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	dst = appendJSONEscaped(dst, s)
+	return append(dst, '"')
+}
+
+// appendJSONEscaped 按 JSON 字符串规则转义 s 后追加（不含两侧引号）。
+// 与 encoding/json 一致地处理控制字符、非法 UTF-8 以及 U+2028/U+2029，但不做 HTML 转义。
+func appendJSONEscaped(dst []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	return append(dst, s[start:]...)
+}
+
+// appendJSONFieldValue 以 JSON 值的形式追加 Field：数字和布尔值不加引号，
+// 非有限浮点数、时长、时间和错误输出为字符串，AnyType 使用 encoding/json 编码
+func appendJSONFieldValue(dst []byte, f Field) []byte {
+	switch f.Type {
+	case IntType, UintType, BoolType:
+		return appendFieldValue(dst, f)
+	case FloatType:
+		v := f.float()
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			dst = append(dst, '"')
+			dst = appendFieldValue(dst, f)
+			return append(dst, '"')
+		}
+		return appendFieldValue(dst, f)
+	case StringType:
+		return appendJSONString(dst, f.str)
+	case AnyType:
+		if data, err := json.Marshal(f.iface); err == nil {
+			return append(dst, data...)
+		}
+		fallthrough
+	default:
+		dst = append(dst, '"')
+		dst = appendFieldValueFunc(dst, f, appendJSONEscaped)
+		return append(dst, '"')
+	}
+}
+
+// appendJSONFields 以 "key":value 的形式追加参数，每个参数前都带逗号
+func appendJSONFields(dst []byte, fields []Field) []byte {
+	positional := 0
+	for _, field := range fields {
+		dst = append(dst, ',', '"')
+		dst = appendFieldKey(dst, field, &positional, appendJSONEscaped)
+		dst = append(dst, '"', ':')
+		dst = appendJSONFieldValue(dst, field)
+	}
+	return dst
+}
+
+// appendFieldKey 追加参数的键名；位置参数没有键名，按出现顺序命名为 arg0、arg1……
+// positional 记录已经出现的位置参数个数
+func appendFieldKey(dst []byte, f Field, positional *int, str stringAppender) []byte {
+	if f.Key != "" {
+		return str(dst, f.Key)
+	}
+	dst = append(dst, "arg"...)
+	dst = strconv.AppendInt(dst, int64(*positional), 10)
+	*positional++
+	return dst
+}
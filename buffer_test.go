@@ -0,0 +1,117 @@
+package ygggo_log
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestAppendJSONString_RoundTrip(t *testing.T) {
+	inputs := []string{
+		"plain",
+		`quote " and backslash \`,
+		"line1\nline2\r\ttab",
+		"ctrl \x00\x1f",
+		"中文消息",
+		"invalid \xff utf8",
+		"sep \u2028 \u2029",
+		"<html> & stuff",
+	}
+
+	for _, in := range inputs {
+		out := appendJSONString(nil, in)
+
+		var decoded string
+		if err := json.Unmarshal(out, &decoded); err != nil {
+			t.Errorf("Invalid JSON %s for input %q: %v", out, in, err)
+			continue
+		}
+		expected, _ := json.Marshal(in)
+		var want string
+		_ = json.Unmarshal(expected, &want)
+		if decoded != want {
+			t.Errorf("Expected %q after round trip, got: %q", want, decoded)
+		}
+	}
+}
+
+func TestJsonFormatter_FieldsInMessage(t *testing.T) {
+	e := acquireEntry(InfoLevel, "say \"hi\"", 0)
+	defer releaseEntry(e)
+	e.Fields = append(e.Fields, String("path", "/a\nb"), Int("n", 3))
+
+	var out []byte
+	w := writerFunc(func(p []byte) (int, error) {
+		out = append(out, p...)
+		return len(p), nil
+	})
+	NewJsonFormatter().FormatEntry(w, e)
+
+	var entry JsonLogEntry
+	if err := json.Unmarshal(out, &entry); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+	if entry.Message != "say \"hi\" path=/a\nb n=3" {
+		t.Errorf("Unexpected message: %q", entry.Message)
+	}
+}
+
+// writerFunc 将函数适配为 io.Writer
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+// logFiveFields 记录一条带五个类型参数的日志，用于零分配测试和基准
+func logFiveFields(logger *Logger, now time.Time) {
+	logger.Log(InfoLevel, "request finished",
+		String("method", "GET"),
+		Int("status", 200),
+		Float64("latency", 12.5),
+		Bool("cached", true),
+		Time("at", now),
+	)
+}
+
+func BenchmarkTextFormatter_FiveFields(b *testing.B) {
+	logger := NewLogger(io.Discard)
+	now := time.Now()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logFiveFields(logger, now)
+	}
+}
+
+func BenchmarkJsonFormatter_FiveFields(b *testing.B) {
+	logger := NewLogger(io.Discard)
+	logger.SetFormatter(NewJsonFormatter())
+	now := time.Now()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logFiveFields(logger, now)
+	}
+}
+
+func BenchmarkJsonFormatter_FiveFieldsParallel(b *testing.B) {
+	logger := NewLogger(io.Discard)
+	logger.SetFormatter(NewJsonFormatter())
+	now := time.Now()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logFiveFields(logger, now)
+		}
+	})
+}
+
+func BenchmarkTextFormatter_VariadicArgs(b *testing.B) {
+	logger := NewLogger(io.Discard)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("request finished", "method=GET", map[string]any{"status": 200}, 12.5, true)
+	}
+}
@@ -0,0 +1,183 @@
+package ygggo_log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrWriterClosed is returned by writes to a BufferedWriter, AsyncWriter,
+// JournaldWriter or network sink after Close.
+var ErrWriterClosed = errors.New("ygggo_log: write to closed writer")
+
+// BufferedWriter defaults.
+const (
+	DefaultBufferSize    = 64 * 1024   // bytes batched before a flush
+	DefaultFlushInterval = time.Second // max time a record stays in memory
+)
+
+// BufferedWriter batches log records in memory in front of a file sink and
+// writes them with a single Write when the buffer would exceed its size, when
+// the oldest buffered record is older than the flush interval, when Flush or
+// Close is called, and right after an entry at or above the flush level
+// (ERROR by default) is logged through a Logger or CombinedFormatter, so
+// errors reach the file immediately. Records are never split between writes.
+//
+// In front of a RotatingWriter, batches never span a rotation: the buffer is
+// flushed before a record that would start a new file, and WillRotate is
+// forwarded so formatters that write per-file headers keep working.
+type BufferedWriter struct {
+	w          io.Writer
+	size       int           // flush threshold in bytes
+	interval   time.Duration // flush interval; 0 disables timed flushes
+	flushLevel LogLevel      // entries at or above this level are flushed immediately
+	buf        []byte
+	timer      *time.Timer
+	closed     bool
+	mu         sync.Mutex
+}
+
+// NewBufferedWriter creates a BufferedWriter that batches up to size bytes
+// (DefaultBufferSize when size <= 0) before writing to w, flushing at least
+// every DefaultFlushInterval and on every ERROR or PANIC entry.
+func NewBufferedWriter(w io.Writer, size int) *BufferedWriter {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &BufferedWriter{
+		w:          w,
+		size:       size,
+		interval:   DefaultFlushInterval,
+		flushLevel: ErrorLevel,
+		buf:        make([]byte, 0, size),
+	}
+}
+
+// SetFlushInterval sets the longest time a record stays buffered; 0 disables
+// timed flushes.
+func (bw *BufferedWriter) SetFlushInterval(interval time.Duration) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	bw.interval = interval
+}
+
+// SetFlushLevel sets the level at or above which entries are flushed as soon
+// as they are written. A level above PanicLevel disables level-triggered flushes.
+func (bw *BufferedWriter) SetFlushLevel(level LogLevel) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	bw.flushLevel = level
+}
+
+// Write buffers one record. Records larger than the buffer are written through
+// directly after the buffered ones.
+func (bw *BufferedWriter) Write(p []byte) (int, error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.closed {
+		return 0, ErrWriterClosed
+	}
+
+	// 缓冲区放不下，或与 p 一起写入会触发轮转时，先写出已缓冲的记录
+	if len(bw.buf) > 0 && (len(bw.buf)+len(p) > bw.size || willRotate(bw.w, len(bw.buf)+len(p))) {
+		if err := bw.flushLocked(); err != nil {
+			return 0, err
+		}
+	}
+	if len(p) >= bw.size {
+		return bw.w.Write(p)
+	}
+	if len(bw.buf) == 0 && bw.interval > 0 {
+		bw.startTimer()
+	}
+	bw.buf = append(bw.buf, p...)
+	return len(p), nil
+}
+
+// Flush writes the buffered records to the underlying writer.
+func (bw *BufferedWriter) Flush() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.flushLocked()
+}
+
+// Close flushes the buffered records, stops timed flushes and closes the
+// underlying writer when it is an io.Closer. Later writes return ErrWriterClosed.
+func (bw *BufferedWriter) Close() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.closed {
+		return nil
+	}
+	bw.closed = true
+	if bw.timer != nil {
+		bw.timer.Stop()
+	}
+	err := bw.flushLocked()
+	if c, ok := bw.w.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// WillRotate reports whether the record of n bytes will start a new file once
+// flushed, when the underlying writer rotates.
+func (bw *BufferedWriter) WillRotate(n int) bool {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return willRotate(bw.w, len(bw.buf)+n)
+}
+
+// entryWritten 在一条日志写入后调用，级别达到 flushLevel 时立即刷新
+func (bw *BufferedWriter) entryWritten(level LogLevel) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if level >= bw.flushLevel {
+		_ = bw.flushLocked()
+	}
+}
+
+// flushLocked 写出缓冲区中的记录，调用方需持有锁
+func (bw *BufferedWriter) flushLocked() error {
+	if len(bw.buf) == 0 {
+		return nil
+	}
+	_, err := bw.w.Write(bw.buf)
+	bw.buf = bw.buf[:0]
+	return err
+}
+
+// startTimer 在缓冲区收到第一条记录时启动定时刷新
+func (bw *BufferedWriter) startTimer() {
+	if bw.timer == nil {
+		bw.timer = time.AfterFunc(bw.interval, func() { _ = bw.Flush() })
+		return
+	}
+	bw.timer.Reset(bw.interval)
+}
+
+// buffered 返回缓冲区中尚未写出的字节数
+func (bw *BufferedWriter) buffered() int {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return len(bw.buf)
+}
+
+// willRotate 判断向 w 写入 n 字节是否会开始新文件
+func willRotate(w io.Writer, n int) bool {
+	r, ok := w.(interface{ WillRotate(n int) bool })
+	return ok && r.WillRotate(n)
+}
+
+// levelFlusher 由需要感知日志级别的写入器实现，Logger 和 CombinedFormatter 在写完一条日志后通知它
+type levelFlusher interface {
+	entryWritten(level LogLevel)
+}
+
+// notifyWritten 通知写入器一条指定级别的日志已写入
+func notifyWritten(w io.Writer, level LogLevel) {
+	if lf, ok := w.(levelFlusher); ok {
+		lf.entryWritten(level)
+	}
+}
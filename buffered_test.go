@@ -0,0 +1,233 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingWriter 记录每次 Write 的内容，可模拟写入失败
+type recordingWriter struct {
+	mu     sync.Mutex
+	writes []string
+	err    error
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *recordingWriter) snapshot() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.writes...)
+}
+
+func TestBufferedWriter_BatchesUntilSize(t *testing.T) {
+	out := &recordingWriter{}
+	bw := NewBufferedWriter(out, 16)
+	bw.SetFlushInterval(0)
+
+	bw.Write([]byte("aaaaa\n"))
+	bw.Write([]byte("bbbbb\n"))
+	if writes := out.snapshot(); len(writes) != 0 {
+		t.Fatalf("Expected records to be buffered, got: %q", writes)
+	}
+	bw.Write([]byte("ccccc\n")) // 放不下时先写出前两条
+	bw.Write([]byte(strings.Repeat("d", 20) + "\n"))
+
+	want := []string{"aaaaa\nbbbbb\n", "ccccc\n", strings.Repeat("d", 20) + "\n"}
+	if writes := out.snapshot(); strings.Join(writes, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got: %q", want, writes)
+	}
+}
+
+func TestBufferedWriter_FlushAndClose(t *testing.T) {
+	out := &recordingWriter{}
+	bw := NewBufferedWriter(out, 0)
+	bw.SetFlushInterval(0)
+
+	bw.Write([]byte("one\n"))
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	bw.Write([]byte("two\n"))
+	if err := bw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if writes := out.snapshot(); len(writes) != 2 || writes[0] != "one\n" || writes[1] != "two\n" {
+		t.Errorf("Unexpected writes: %q", writes)
+	}
+	if _, err := bw.Write([]byte("late\n")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed, got: %v", err)
+	}
+}
+
+func TestBufferedWriter_FlushInterval(t *testing.T) {
+	out := &recordingWriter{}
+	bw := NewBufferedWriter(out, 0)
+	bw.SetFlushInterval(10 * time.Millisecond)
+	defer bw.Close()
+
+	bw.Write([]byte("tick\n"))
+	deadline := time.Now().Add(2 * time.Second)
+	for len(out.snapshot()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if writes := out.snapshot(); len(writes) != 1 || writes[0] != "tick\n" {
+		t.Errorf("Expected a timed flush, got: %q", writes)
+	}
+}
+
+func TestBufferedWriter_FlushLevel(t *testing.T) {
+	out := &recordingWriter{}
+	bw := NewBufferedWriter(out, 0)
+	bw.SetFlushInterval(0)
+	logger := NewLogger(bw)
+
+	logger.Info("buffered")
+	logger.Warning("still buffered")
+	if writes := out.snapshot(); len(writes) != 0 {
+		t.Fatalf("Expected INFO and WARNING to stay buffered, got: %q", writes)
+	}
+	logger.Error("flushed")
+	writes := out.snapshot()
+	if len(writes) != 1 || strings.Count(writes[0], "\n") != 3 || !strings.Contains(writes[0], "flushed") {
+		t.Errorf("Expected ERROR to flush all three records at once, got: %q", writes)
+	}
+}
+
+func TestBufferedWriter_CombinedFormatterFlushLevel(t *testing.T) {
+	out := &recordingWriter{}
+	bw := NewBufferedWriter(out, 0)
+	bw.SetFlushInterval(0)
+	bw.SetFlushLevel(WarningLevel)
+	logger := NewLogger(nil)
+	logger.SetFormatter(NewCombinedFormatterWith(nil, nil, bw, NewJsonFormatter()))
+
+	logger.Info("buffered")
+	if len(out.snapshot()) != 0 {
+		t.Fatal("Expected INFO to stay buffered")
+	}
+	logger.Warning("flushed")
+	if writes := out.snapshot(); len(writes) != 1 {
+		t.Errorf("Expected WARNING to flush, got: %q", writes)
+	}
+}
+
+func TestBufferedWriter_ErrorPropagation(t *testing.T) {
+	out := &recordingWriter{err: errors.New("disk full")}
+	bw := NewBufferedWriter(out, 8)
+	bw.SetFlushInterval(0)
+
+	if _, err := bw.Write([]byte("12345\n")); err != nil {
+		t.Fatalf("Expected buffered write to succeed, got: %v", err)
+	}
+	if _, err := bw.Write([]byte("67890\n")); err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected the flush error, got: %v", err)
+	}
+	if err := bw.Flush(); err != nil {
+		t.Errorf("Expected nothing left to flush, got: %v", err)
+	}
+}
+
+func TestBufferedWriter_RotationBoundaries(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.csv")
+	rw, err := NewRotatingWriter(filename, 60, 5)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	bw := NewBufferedWriter(rw, 0)
+	bw.SetFlushInterval(0)
+	logger := NewLogger(bw)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewCSVFormatter(WithColumns(ColumnMessage), WithHeader()))
+
+	for i := 0; i < 12; i++ {
+		logger.Info("message")
+	}
+	bw.Close()
+
+	// 每个文件都以列名行开头，且不超过大小限制
+	files, _ := filepath.Glob(filename + "*")
+	if len(files) < 3 {
+		t.Fatalf("Expected several rotated files, got: %v", files)
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if !bytes.HasPrefix(data, []byte("message\r\n")) || len(data) > 60 {
+			t.Errorf("Unexpected content of %s: %q", filepath.Base(f), data)
+		}
+	}
+}
+
+func TestNewLoggerFromConfig_FileBuffer(t *testing.T) {
+	config := LoadConfigFromEnv()
+	config.OutputFile = filepath.Join(t.TempDir(), "app.log")
+	config.FileBuffer = 4096
+	config.FlushInterval = 0
+	logger := NewLoggerFromConfig(config)
+
+	combined := logger.formatter.(*CombinedFormatter)
+	bw, ok := combined.file.(*BufferedWriter)
+	if !ok {
+		t.Fatalf("Expected a BufferedWriter, got: %T", combined.file)
+	}
+	defer bw.Close()
+
+	logger.Info("buffered")
+	if data, _ := os.ReadFile(config.OutputFile); len(data) != 0 {
+		t.Errorf("Expected INFO to stay buffered, got: %q", data)
+	}
+	logger.Error("flushed")
+	if data, _ := os.ReadFile(config.OutputFile); !bytes.Contains(data, []byte("buffered")) || !bytes.Contains(data, []byte("flushed")) {
+		t.Errorf("Expected both records after ERROR, got: %q", data)
+	}
+}
+
+func TestLoadConfigFromEnv_FileBuffer(t *testing.T) {
+	config := LoadConfigFromEnv()
+	if config.FileBuffer != 0 || config.FlushInterval != time.Second || config.FlushLevel != ErrorLevel {
+		t.Errorf("Unexpected defaults: %d %v %v", config.FileBuffer, config.FlushInterval, config.FlushLevel)
+	}
+
+	os.Setenv("YGGGO_LOG_FILE_BUFFER", "256KB")
+	os.Setenv("YGGGO_LOG_FLUSH_INTERVAL", "250ms")
+	os.Setenv("YGGGO_LOG_FLUSH_LEVEL", "warning")
+	defer os.Unsetenv("YGGGO_LOG_FILE_BUFFER")
+	defer os.Unsetenv("YGGGO_LOG_FLUSH_INTERVAL")
+	defer os.Unsetenv("YGGGO_LOG_FLUSH_LEVEL")
+
+	config = LoadConfigFromEnv()
+	if len(config.Errors) != 0 {
+		t.Errorf("Unexpected config errors: %v", config.Errors)
+	}
+	if config.FileBuffer != 256<<10 || config.FlushInterval != 250*time.Millisecond || config.FlushLevel != WarningLevel {
+		t.Errorf("Unexpected buffer config: %d %v %v", config.FileBuffer, config.FlushInterval, config.FlushLevel)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidFileBuffer(t *testing.T) {
+	os.Setenv("YGGGO_LOG_FILE_BUFFER", "64 KB")
+	defer os.Unsetenv("YGGGO_LOG_FILE_BUFFER")
+
+	config := LoadConfigFromEnv()
+	if config.FileBuffer != 0 {
+		t.Errorf("Expected an invalid buffer size to leave output unbuffered, got: %d", config.FileBuffer)
+	}
+	if len(config.Errors) != 1 || !strings.Contains(config.Errors[0].Error(), "YGGGO_LOG_FILE_BUFFER") {
+		t.Errorf("Expected a config error, got: %v", config.Errors)
+	}
+}
@@ -1,16 +1,35 @@
 package ygggo_log
 
 import (
-	"path/filepath"
 	"runtime"
 )
 
-func callerInfo() (string, int) {
-	// 3 层栈：callerInfo -> ColorFormatter.Format -> Logger.log -> Debug/Info
-	_, file, line, ok := runtime.Caller(3)
-	if !ok {
+// Caller 返回产生这条日志的源文件完整路径和行号；未知时返回 "?" 和 0。
+// 调用位置在记录日志时只保存程序计数器，解析推迟到格式化器真正需要时进行。
+func (e *Entry) Caller() (string, int) {
+	frame := e.CallerFrame()
+	if frame.File == "" {
 		return "?", 0
 	}
-	return filepath.Base(file), line
+	return frame.File, frame.Line
 }
 
+// CallerFrame 返回产生这条日志的完整栈帧信息（含函数名）；未知时返回零值
+func (e *Entry) CallerFrame() runtime.Frame {
+	if e.pc == 0 {
+		if e.file == "" {
+			return runtime.Frame{}
+		}
+		return runtime.Frame{File: e.file, Line: e.line, Function: e.function}
+	}
+	frames := runtime.CallersFrames([]uintptr{e.pc})
+	frame, _ := frames.Next()
+	return frame
+}
+
+// SetCaller 直接设置调用位置，用于重新格式化已保存的日志（如从二进制日志解码出的记录）；
+// 设置后 Caller 和 CallerFrame 返回这里给出的值
+func (e *Entry) SetCaller(file string, line int, function string) {
+	e.pc = 0
+	e.file, e.line, e.function = file, line, function
+}
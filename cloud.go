@@ -0,0 +1,436 @@
+package ygggo_log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CloudProfile 云平台 JSON 日志布局
+type CloudProfile int
+
+const (
+	// GCPProfile Google Cloud Logging 结构化日志：severity、time、
+	// logging.googleapis.com/sourceLocation 和 trace/spanId
+	GCPProfile CloudProfile = iota
+	// DatadogProfile Datadog 日志：status、service、logger.*、error.* 和 dd.trace_id/dd.span_id
+	DatadogProfile
+	// CloudWatchProfile AWS CloudWatch Logs：与 Lambda JSON 日志一致的扁平布局，
+	// 配置指标命名空间后附带 Embedded Metric Format 的 _aws 元数据
+	CloudWatchProfile
+)
+
+// String 返回布局名称
+func (p CloudProfile) String() string {
+	switch p {
+	case GCPProfile:
+		return "gcp"
+	case DatadogProfile:
+		return "datadog"
+	case CloudWatchProfile:
+		return "cloudwatch"
+	default:
+		return "unknown"
+	}
+}
+
+// WithProjectID 设置 GCP 项目 ID，用于拼接 logging.googleapis.com/trace；
+// 默认读取 GOOGLE_CLOUD_PROJECT 环境变量
+func WithProjectID(projectID string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.projectID = projectID
+	}
+}
+
+// WithMetricNamespace 设置 CloudWatch 指标命名空间。设置后，带数值参数的日志会附带
+// Embedded Metric Format 的 _aws 元数据，把每个数值参数声明为指标，维度见 WithMetricDimensions
+func WithMetricNamespace(namespace string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.metricNamespace = namespace
+	}
+}
+
+// WithMetricDimensions 设置 CloudWatch 指标的维度，即日志中作为维度值的顶层键名，
+// 如 service、env（可来自常量字段）；默认只有 service
+func WithMetricDimensions(keys ...string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.metricDimensions = keys
+	}
+}
+
+// CloudFormatter 按云平台约定的键名和级别名称输出 JSON 行。
+// 未被映射的参数作为顶层字段输出，位置参数依次命名为 arg0、arg1……；键名与布局内置字段
+// 相同的参数（如 GCP 的 severity、Datadog 的 status）加 labels. 前缀，不会覆盖内置字段。
+// 常量字段 service 作为服务名（GCP 为 serviceContext.service），GCP 的 version 映射到
+// serviceContext.version，Datadog 的 host 替换主机名；其余与布局内置字段同名的常量字段被忽略
+type CloudFormatter struct {
+	profile     CloudProfile
+	time        timeEncoder
+	service     string
+	showService bool   // CloudWatch 未配置指标命名空间时也输出 service
+	version     string // GCP serviceContext.version
+	hostname    string
+	projectID   string
+	namespace   string
+	dimensions  []byte // 预先编码的 EMF 维度，如 [["service"]]
+	static      []byte // 预先编码的常量字段
+}
+
+// NewCloudFormatter 创建指定云平台布局的格式化器，时间默认使用 UTC
+func NewCloudFormatter(profile CloudProfile, opts ...FormatterOption) *CloudFormatter {
+	o := newFormatterOptions(opts)
+	if o.timeLocation == nil {
+		o.timeLocation = time.UTC
+	}
+	projectID := o.projectID
+	if projectID == "" {
+		projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	defaultLayout := "2006-01-02T15:04:05.000Z07:00"
+	if profile == GCPProfile {
+		defaultLayout = time.RFC3339Nano
+	}
+	f := &CloudFormatter{
+		profile:   profile,
+		time:      newTimeEncoder(o, defaultLayout),
+		hostname:  hostname(),
+		projectID: projectID,
+		namespace: o.metricNamespace,
+	}
+
+	name, static := takeStaticField(o.staticFields, "service")
+	if o.serviceName == "" {
+		o.serviceName = name
+	}
+	f.service = o.service()
+	f.showService = name != "" || f.namespace != ""
+	switch profile {
+	case GCPProfile:
+		f.version, static = takeStaticField(static, "version")
+	case DatadogProfile:
+		var host string
+		if host, static = takeStaticField(static, "host"); host != "" {
+			f.hostname = host
+		}
+	}
+	f.static = appendJSONFields(nil, dropStaticFields(static, profile.reserved))
+
+	dimensions := o.metricDimensions
+	if len(dimensions) == 0 {
+		dimensions = []string{"service"}
+	}
+	f.dimensions = append(f.dimensions, "[["...)
+	for i, key := range dimensions {
+		if i > 0 {
+			f.dimensions = append(f.dimensions, ',')
+		}
+		f.dimensions = appendJSONString(f.dimensions, key)
+	}
+	f.dimensions = append(f.dimensions, "]]"...)
+	return f
+}
+
+// cloudReservedKeys 各布局自身输出的顶层字段
+var cloudReservedKeys = map[CloudProfile][]string{
+	GCPProfile:        {"severity", "message", "time", "serviceContext"},
+	DatadogProfile:    {"timestamp", "status", "message", "service", "host", "logger", "dd.trace_id", "dd.span_id", "error"},
+	CloudWatchProfile: {"timestamp", "level", "message", "caller", "service", "_aws"},
+}
+
+// reserved 判断键名是否与布局内置的字段相同
+func (p CloudProfile) reserved(key string) bool {
+	if p == GCPProfile && strings.HasPrefix(key, "logging.googleapis.com/") {
+		return true
+	}
+	return slices.Contains(cloudReservedKeys[p], key)
+}
+
+// Profile 返回格式化器使用的云平台布局
+func (f *CloudFormatter) Profile() CloudProfile {
+	return f.profile
+}
+
+// Format 格式化为云平台 JSON
+func (f *CloudFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为云平台 JSON
+func (f *CloudFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	switch f.profile {
+	case DatadogProfile:
+		buf.b = f.appendDatadog(buf.b, e)
+	case CloudWatchProfile:
+		buf.b = f.appendCloudWatch(buf.b, e)
+	default:
+		buf.b = f.appendGCP(buf.b, e)
+	}
+	buf.b = append(buf.b, '}', '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendGCP 追加 Google Cloud Logging 布局（不含结尾的 }）
+func (f *CloudFormatter) appendGCP(dst []byte, e *Entry) []byte {
+	dst = append(dst, `{"severity":"`...)
+	dst = append(dst, gcpSeverity(e.Level)...)
+	dst = append(dst, `","message":`...)
+	dst = appendJSONString(dst, e.Message)
+	dst = append(dst, `,"time":`...)
+	dst = f.time.appendJSON(dst, e.Time)
+
+	frame := e.CallerFrame()
+	if frame.File != "" {
+		dst = append(dst, `,"logging.googleapis.com/sourceLocation":{"file":`...)
+		dst = appendJSONString(dst, frame.File)
+		dst = append(dst, `,"line":"`...)
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, `","function":`...)
+		dst = appendJSONString(dst, frame.Function)
+		dst = append(dst, '}')
+	}
+
+	if id, ok := fieldString(e.Fields, TraceIDKey); ok {
+		dst = append(dst, `,"logging.googleapis.com/trace":"`...)
+		if f.projectID != "" {
+			dst = append(dst, "projects/"...)
+			dst = appendJSONEscaped(dst, f.projectID)
+			dst = append(dst, "/traces/"...)
+		}
+		dst = appendJSONEscaped(dst, id)
+		dst = append(dst, '"')
+	}
+	if id, ok := fieldString(e.Fields, SpanIDKey); ok {
+		dst = append(dst, `,"logging.googleapis.com/spanId":`...)
+		dst = appendJSONString(dst, id)
+	}
+
+	dst = append(dst, `,"serviceContext":{"service":`...)
+	dst = appendJSONString(dst, f.service)
+	if f.version != "" {
+		dst = append(dst, `,"version":`...)
+		dst = appendJSONString(dst, f.version)
+	}
+	dst = append(dst, '}')
+	dst = append(dst, f.static...)
+	return f.appendFields(dst, e.Fields, -1, true)
+}
+
+// appendDatadog 追加 Datadog 布局（不含结尾的 }）
+func (f *CloudFormatter) appendDatadog(dst []byte, e *Entry) []byte {
+	dst = append(dst, `{"timestamp":`...)
+	dst = f.time.appendJSON(dst, e.Time)
+	dst = append(dst, `,"status":"`...)
+	dst = append(dst, datadogStatus(e.Level)...)
+	dst = append(dst, `","message":`...)
+	dst = appendJSONString(dst, e.Message)
+	dst = append(dst, `,"service":`...)
+	dst = appendJSONString(dst, f.service)
+	if f.hostname != "" {
+		dst = append(dst, `,"host":`...)
+		dst = appendJSONString(dst, f.hostname)
+	}
+
+	frame := e.CallerFrame()
+	if frame.File != "" {
+		dst = append(dst, `,"logger":{"file_name":`...)
+		dst = appendJSONString(dst, filepath.Base(frame.File))
+		dst = append(dst, `,"line":`...)
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, `,"method_name":`...)
+		dst = appendJSONString(dst, frame.Function)
+		dst = append(dst, '}')
+	}
+
+	if id, ok := fieldString(e.Fields, TraceIDKey); ok {
+		dst = append(dst, `,"dd.trace_id":`...)
+		dst = appendJSONString(dst, datadogID(id))
+	}
+	if id, ok := fieldString(e.Fields, SpanIDKey); ok {
+		dst = append(dst, `,"dd.span_id":`...)
+		dst = appendJSONString(dst, datadogID(id))
+	}
+
+	errIndex := errorFieldIndex(e.Fields)
+	if errIndex >= 0 {
+		dst = append(dst, `,"error":{"kind":`...)
+		dst = appendJSONString(dst, fmt.Sprintf("%T", e.Fields[errIndex].iface))
+		dst = append(dst, `,"message":"`...)
+		dst = appendFieldValueFunc(dst, e.Fields[errIndex], appendJSONEscaped)
+		dst = append(dst, `"}`...)
+	}
+	dst = append(dst, f.static...)
+	return f.appendFields(dst, e.Fields, errIndex, true)
+}
+
+// appendCloudWatch 追加 CloudWatch 布局（不含结尾的 }）
+func (f *CloudFormatter) appendCloudWatch(dst []byte, e *Entry) []byte {
+	dst = append(dst, `{"timestamp":`...)
+	dst = f.time.appendJSON(dst, e.Time)
+	dst = append(dst, `,"level":"`...)
+	dst = append(dst, cloudWatchLevel(e.Level)...)
+	dst = append(dst, `","message":`...)
+	dst = appendJSONString(dst, e.Message)
+
+	file, line := e.Caller()
+	dst = append(dst, `,"caller":"`...)
+	dst = appendJSONEscaped(dst, filepath.Base(file))
+	dst = append(dst, ':')
+	dst = strconv.AppendInt(dst, int64(line), 10)
+	dst = append(dst, '"')
+
+	if f.showService {
+		dst = append(dst, `,"service":`...)
+		dst = appendJSONString(dst, f.service)
+	}
+	if f.namespace != "" {
+		dst = f.appendEMF(dst, e)
+	}
+	dst = append(dst, f.static...)
+	return f.appendFields(dst, e.Fields, -1, false)
+}
+
+// appendEMF 追加 Embedded Metric Format 元数据，没有数值参数时不输出
+func (f *CloudFormatter) appendEMF(dst []byte, e *Entry) []byte {
+	positional := 0
+	first := true
+	for _, field := range e.Fields {
+		numeric := field.Type == IntType || field.Type == UintType || field.Type == FloatType
+		if !numeric {
+			if field.Key == "" {
+				positional++
+			}
+			continue
+		}
+		if first {
+			dst = append(dst, `,"_aws":{"Timestamp":`...)
+			dst = strconv.AppendInt(dst, e.Time.UnixMilli(), 10)
+			dst = append(dst, `,"CloudWatchMetrics":[{"Namespace":`...)
+			dst = appendJSONString(dst, f.namespace)
+			dst = append(dst, `,"Dimensions":`...)
+			dst = append(dst, f.dimensions...)
+			dst = append(dst, `,"Metrics":[`...)
+			first = false
+		} else {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `{"Name":"`...)
+		dst = f.appendKey(dst, field, &positional)
+		dst = append(dst, `","Unit":"None"}`...)
+	}
+	if !first {
+		dst = append(dst, `]}]}`...)
+	}
+	return dst
+}
+
+// appendFields 以顶层字段追加参数，跳过下标为 skip 的参数；
+// skipTrace 为 true 时跳过已映射到追踪字段的 trace_id/span_id
+func (f *CloudFormatter) appendFields(dst []byte, fields []Field, skip int, skipTrace bool) []byte {
+	positional := 0
+	for i, field := range fields {
+		if i == skip || (skipTrace && (field.Key == TraceIDKey || field.Key == SpanIDKey)) {
+			continue
+		}
+		dst = append(dst, ',', '"')
+		dst = f.appendKey(dst, field, &positional)
+		dst = append(dst, '"', ':')
+		dst = appendJSONFieldValue(dst, field)
+	}
+	return dst
+}
+
+// appendKey 追加参数的键名，与布局内置字段同名的键加 labels. 前缀，避免覆盖级别、消息等字段
+func (f *CloudFormatter) appendKey(dst []byte, field Field, positional *int) []byte {
+	if f.profile.reserved(field.Key) {
+		dst = append(dst, "labels."...)
+	}
+	return appendFieldKey(dst, field, positional, appendJSONEscaped)
+}
+
+// fieldString 查找指定键的参数并返回其文本值
+func fieldString(fields []Field, key string) (string, bool) {
+	for _, f := range fields {
+		if f.Key == key {
+			return f.String(), true
+		}
+	}
+	return "", false
+}
+
+// gcpSeverity 将日志级别映射为 Cloud Logging 的 LogSeverity
+func gcpSeverity(level LogLevel) string {
+	switch level {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarningLevel:
+		return "WARNING"
+	case ErrorLevel:
+		return "ERROR"
+	case PanicLevel:
+		return "CRITICAL"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// datadogStatus 将日志级别映射为 Datadog 的 status
+func datadogStatus(level LogLevel) string {
+	switch level {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarningLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case PanicLevel:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// cloudWatchLevel 将日志级别映射为 Lambda JSON 日志使用的级别名称
+func cloudWatchLevel(level LogLevel) string {
+	switch level {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarningLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	case PanicLevel:
+		return "FATAL"
+	default:
+		return "INFO"
+	}
+}
+
+// datadogID 将十六进制的 W3C trace/span id 转换为 Datadog 使用的十进制 64 位 id（取低 64 位）。
+// 32 个字符的 id 总是按十六进制解析；16 个字符的 id 含 a-f 时按十六进制解析，
+// 全为数字时视为已是 Datadog 的十进制 id。其他长度或无法解析时原样返回
+func datadogID(id string) string {
+	switch {
+	case len(id) == 32:
+	case len(id) == 16 && strings.ContainsAny(id, "abcdefABCDEF"):
+	default:
+		return id
+	}
+	v, err := strconv.ParseUint(id[len(id)-16:], 16, 64)
+	if err != nil {
+		return id
+	}
+	return strconv.FormatUint(v, 10)
+}
@@ -0,0 +1,291 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// decodeLine 将一行 JSON 日志解析为 map
+func decodeLine(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, data)
+	}
+	return doc
+}
+
+func TestCloudFormatter_GCP(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewCloudFormatter(GCPProfile, WithProjectID("demo"), WithServiceName("api")))
+
+	logger.Warning("slow", TraceID("4bf92f3577b34da6a3ce929d0e0e4736"), SpanID("00f067aa0ba902b7"), "path=/a")
+
+	doc := decodeLine(t, buf.Bytes())
+	if doc["severity"] != "WARNING" || doc["message"] != "slow" {
+		t.Errorf("Unexpected severity/message: %v", doc)
+	}
+	if doc["time"] != "2025-06-07T00:09:10.123456789Z" {
+		t.Errorf("Unexpected time: %v", doc["time"])
+	}
+	loc, ok := doc["logging.googleapis.com/sourceLocation"].(map[string]any)
+	if !ok || !strings.HasSuffix(loc["file"].(string), "cloud_test.go") || loc["line"] == "0" {
+		t.Errorf("Unexpected sourceLocation: %v", doc["logging.googleapis.com/sourceLocation"])
+	}
+	if !strings.HasSuffix(loc["function"].(string), "TestCloudFormatter_GCP") {
+		t.Errorf("Unexpected function: %v", loc["function"])
+	}
+	if doc["logging.googleapis.com/trace"] != "projects/demo/traces/4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Unexpected trace: %v", doc["logging.googleapis.com/trace"])
+	}
+	if doc["logging.googleapis.com/spanId"] != "00f067aa0ba902b7" {
+		t.Errorf("Unexpected spanId: %v", doc["logging.googleapis.com/spanId"])
+	}
+	if _, ok := doc[TraceIDKey]; ok {
+		t.Error("Expected trace_id to be mapped, not repeated as a field")
+	}
+	if doc["path"] != "/a" {
+		t.Errorf("Expected custom field path, got: %v", doc["path"])
+	}
+}
+
+func TestCloudFormatter_GCPPanicSeverity(t *testing.T) {
+	if gcpSeverity(PanicLevel) != "CRITICAL" {
+		t.Errorf("Expected PANIC to map to CRITICAL, got: %s", gcpSeverity(PanicLevel))
+	}
+}
+
+func TestCloudFormatter_Datadog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewCloudFormatter(DatadogProfile, WithServiceName("api")))
+
+	logger.Error("failed", errors.New("timeout"), TraceID("4bf92f3577b34da6a3ce929d0e0e4736"), Int("retries", 3))
+
+	doc := decodeLine(t, buf.Bytes())
+	if doc["status"] != "error" || doc["service"] != "api" {
+		t.Errorf("Unexpected status/service: %v", doc)
+	}
+	// 低 64 位 a3ce929d0e0e4736 的十进制
+	if doc["dd.trace_id"] != "11803532876627986230" {
+		t.Errorf("Unexpected dd.trace_id: %v", doc["dd.trace_id"])
+	}
+	errObj, ok := doc["error"].(map[string]any)
+	if !ok || errObj["message"] != "timeout" || errObj["kind"] != "*errors.errorString" {
+		t.Errorf("Unexpected error object: %v", doc["error"])
+	}
+	if doc["retries"] != float64(3) {
+		t.Errorf("Expected retries=3, got: %v", doc["retries"])
+	}
+	if datadogStatus(WarningLevel) != "warn" {
+		t.Errorf("Expected WARNING to map to warn")
+	}
+}
+
+func TestCloudFormatter_CloudWatchEMF(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewCloudFormatter(CloudWatchProfile, WithServiceName("api"), WithMetricNamespace("Shop")))
+
+	logger.Warning("checkout", Float64("latency", 12.5), String("user", "tom"))
+
+	doc := decodeLine(t, buf.Bytes())
+	if doc["level"] != "WARN" || doc["timestamp"] != "2025-06-07T00:09:10.123Z" {
+		t.Errorf("Unexpected level/timestamp: %v", doc)
+	}
+	if doc["latency"] != 12.5 || doc["service"] != "api" {
+		t.Errorf("Expected metric and dimension values at top level: %v", doc)
+	}
+	aws, ok := doc["_aws"].(map[string]any)
+	if !ok || aws["Timestamp"] != float64(fixedClock().UnixMilli()) {
+		t.Fatalf("Unexpected _aws block: %v", doc["_aws"])
+	}
+	metrics := aws["CloudWatchMetrics"].([]any)[0].(map[string]any)
+	if metrics["Namespace"] != "Shop" {
+		t.Errorf("Unexpected namespace: %v", metrics["Namespace"])
+	}
+	names := metrics["Metrics"].([]any)
+	if len(names) != 1 || names[0].(map[string]any)["Name"] != "latency" {
+		t.Errorf("Expected latency metric only, got: %v", names)
+	}
+}
+
+func TestCloudFormatter_CloudWatchWithoutNamespace(t *testing.T) {
+	var buf bytes.Buffer
+	NewCloudFormatter(CloudWatchProfile).Format(&buf, PanicLevel, "boom")
+
+	doc := decodeLine(t, buf.Bytes())
+	if doc["level"] != "FATAL" {
+		t.Errorf("Expected PANIC to map to FATAL, got: %v", doc["level"])
+	}
+	if _, ok := doc["_aws"]; ok {
+		t.Error("Expected no _aws block without a metric namespace")
+	}
+}
+
+func TestCloudFormatter_StaticFieldsDoNotCollide(t *testing.T) {
+	static := WithStaticFields(String("service", "api"), String("version", "1.2.0"),
+		String("message", "static"), String("status", "x"), String("level", "x"), String("team", "core"))
+	for _, profile := range []CloudProfile{GCPProfile, DatadogProfile, CloudWatchProfile} {
+		var buf bytes.Buffer
+		logger := NewLogger(&buf)
+		logger.SetFormatter(NewCloudFormatter(profile, static))
+		logger.Info("started")
+
+		out := buf.String()
+		for _, key := range []string{`"service":`, `"message":`, `"status":`, `"level":`} {
+			if n := strings.Count(out, key); n > 1 {
+				t.Errorf("%s: expected %s at most once, got %d in: %s", profile, key, n, out)
+			}
+		}
+		doc := decodeLine(t, buf.Bytes())
+		if doc["message"] != "started" || doc["team"] != "core" {
+			t.Errorf("%s: unexpected message or static field: %v", profile, doc)
+		}
+		switch profile {
+		case GCPProfile:
+			ctx, _ := doc["serviceContext"].(map[string]any)
+			if ctx["service"] != "api" || ctx["version"] != "1.2.0" {
+				t.Errorf("gcp: expected service and version in serviceContext, got: %v", doc["serviceContext"])
+			}
+			if _, ok := doc["version"]; ok {
+				t.Errorf("gcp: expected version to be mapped, not repeated: %v", doc)
+			}
+		case DatadogProfile:
+			if doc["service"] != "api" || doc["status"] != "info" || doc["version"] != "1.2.0" {
+				t.Errorf("datadog: unexpected service/status/version: %v", doc)
+			}
+		case CloudWatchProfile:
+			if doc["service"] != "api" || doc["level"] != "INFO" {
+				t.Errorf("cloudwatch: unexpected service/level: %v", doc)
+			}
+		}
+	}
+}
+
+func TestLoadConfigFromEnv_CloudFormats(t *testing.T) {
+	testCases := map[string]LogFormat{
+		"gcp":        GCPFormat,
+		"datadog":    DatadogFormat,
+		"cloudwatch": CloudWatchFormat,
+	}
+	for name, expected := range testCases {
+		os.Setenv("YGGGO_LOG_FORMAT", name)
+		config := LoadConfigFromEnv()
+		if config.Format != expected || config.Format.String() != name {
+			t.Errorf("Expected %v for %s, got: %v", expected, name, config.Format)
+		}
+	}
+	os.Unsetenv("YGGGO_LOG_FORMAT")
+}
+
+func TestCloudFormatter_CloudWatchDimensions(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewCloudFormatter(CloudWatchProfile, WithServiceName("api"), WithMetricNamespace("Shop"),
+		WithMetricDimensions("service", "env"), WithStaticFields(String("env", "prod"))))
+	logger.Info("checkout", Int("items", 2))
+
+	if !strings.Contains(buf.String(), `"Dimensions":[["service","env"]]`) {
+		t.Errorf("Expected configured dimensions, got: %s", buf.String())
+	}
+	if doc := decodeLine(t, buf.Bytes()); doc["env"] != "prod" {
+		t.Errorf("Expected the env dimension value at top level, got: %v", doc)
+	}
+}
+
+func TestLoadConfigFromEnv_CloudSettings(t *testing.T) {
+	env := map[string]string{
+		"YGGGO_LOG_FORMAT":            "cloudwatch",
+		"YGGGO_LOG_SERVICE_NAME":      "api",
+		"YGGGO_LOG_STATIC_FIELDS":     "env=prod",
+		"YGGGO_LOG_METRIC_NAMESPACE":  "Shop",
+		"YGGGO_LOG_METRIC_DIMENSIONS": "service, env",
+		"YGGGO_LOG_GCP_PROJECT":       "demo",
+	}
+	for key, value := range env {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	config := LoadConfigFromEnv()
+	if config.ProjectID != "demo" || config.MetricNamespace != "Shop" || len(config.MetricDimensions) != 2 {
+		t.Errorf("Unexpected cloud settings: %q %q %v", config.ProjectID, config.MetricNamespace, config.MetricDimensions)
+	}
+
+	var buf bytes.Buffer
+	NewLoggerFromEnvWithOutput(&buf).Info("checkout", Int("items", 2))
+	if !strings.Contains(buf.String(), `"Namespace":"Shop","Dimensions":[["service","env"]]`) {
+		t.Errorf("Expected metrics from the environment, got: %s", buf.String())
+	}
+
+	os.Setenv("YGGGO_LOG_FORMAT", "gcp")
+	buf.Reset()
+	NewLoggerFromEnvWithOutput(&buf).Info("traced", TraceID("4bf92f3577b34da6a3ce929d0e0e4736"))
+	if !strings.Contains(buf.String(), `"logging.googleapis.com/trace":"projects/demo/traces/4bf92f3577b34da6a3ce929d0e0e4736"`) {
+		t.Errorf("Expected the project ID from the environment, got: %s", buf.String())
+	}
+}
+
+func TestCloudFormatter_FieldsDoNotCollide(t *testing.T) {
+	testCases := []struct {
+		profile CloudProfile
+		level   string // 布局的级别键名
+		want    string // 级别值
+	}{
+		{GCPProfile, "severity", "INFO"},
+		{DatadogProfile, "status", "info"},
+		{CloudWatchProfile, "level", "INFO"},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		logger := NewLogger(&buf)
+		logger.SetFormatter(NewCloudFormatter(tc.profile, WithMetricNamespace("Shop")))
+		logger.Info("real", String(tc.level, "spoof"), String("message", "spoof"), Int("timestamp", 1))
+
+		out := buf.String()
+		for _, key := range []string{`"` + tc.level + `":`, `"message":`} {
+			if n := strings.Count(out, key); n != 1 {
+				t.Errorf("%s: expected %s exactly once, got %d in: %s", tc.profile, key, n, out)
+			}
+		}
+		doc := decodeLine(t, buf.Bytes())
+		if doc[tc.level] != tc.want || doc["message"] != "real" {
+			t.Errorf("%s: expected built-in level and message to win, got: %v", tc.profile, doc)
+		}
+		if doc["labels."+tc.level] != "spoof" || doc["labels.message"] != "spoof" {
+			t.Errorf("%s: expected colliding fields under labels., got: %v", tc.profile, doc)
+		}
+	}
+
+	// CloudWatch 指标名与改名后的顶层键一致
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewCloudFormatter(CloudWatchProfile, WithMetricNamespace("Shop")))
+	logger.Info("metric", Int("timestamp", 1))
+	if !strings.Contains(buf.String(), `{"Name":"labels.timestamp","Unit":"None"}`) || !strings.Contains(buf.String(), `"labels.timestamp":1`) {
+		t.Errorf("Expected the metric to use the renamed key, got: %s", buf.String())
+	}
+}
+
+func TestDatadogID(t *testing.T) {
+	testCases := map[string]string{
+		"4bf92f3577b34da6a3ce929d0e0e4736": "11803532876627986230", // W3C trace id，取低 64 位
+		"00f067aa0ba902b7":                 "67667974448284343",    // W3C span id
+		"1234567890123456":                 "1234567890123456",     // 16 位十进制 Datadog id
+		"12345":                            "12345",
+		"zzzzzzzzzzzzzzzz":                 "zzzzzzzzzzzzzzzz",
+	}
+	for id, want := range testCases {
+		if got := datadogID(id); got != want {
+			t.Errorf("datadogID(%q) = %s, want %s", id, got, want)
+		}
+	}
+}
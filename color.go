@@ -1,9 +1,10 @@
 package ygggo_log
 
 import (
-	"fmt"
 	"io"
-	"time"
+	"path/filepath"
+	"runtime"
+	"strconv"
 )
 
 // ANSI颜色代码常量
@@ -19,42 +20,123 @@ const (
 )
 
 // ColorFormatter 彩色格式化器
-type ColorFormatter struct{}
+type ColorFormatter struct {
+	time    timeEncoder
+	palette *palette
+	link    *callerLink
+	path    bool // 调用位置显示相对路径
+	raw     bool // 不转义消息和参数
+}
 
-// NewColorFormatter 创建彩色格式化器
-func NewColorFormatter() *ColorFormatter {
-	return &ColorFormatter{}
+// NewColorFormatter 创建彩色格式化器，默认时间布局为 2006-01-02 15:04:05.000，
+// 配色通过 WithTheme 设置，调用位置链接通过 WithCallerLink 设置
+func NewColorFormatter(opts ...FormatterOption) *ColorFormatter {
+	o := newFormatterOptions(opts)
+	return &ColorFormatter{
+		time:    newTimeEncoder(o, "2006-01-02 15:04:05.000"),
+		palette: o.palette(),
+		link:    newCallerLink(o.callerLink),
+		path:    o.callerPath,
+		raw:     o.rawText,
+	}
 }
 
 // Format 格式化为彩色文本格式
 func (f *ColorFormatter) Format(writer io.Writer, level LogLevel, message string) {
-	// 时间：年月日时分秒.毫秒
-	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-	colorCode := getColorCode(level)
-	resetCode := getResetCode()
-	file, line := callerInfo()
+	formatMessage(f, writer, level, message)
+}
 
-	logEntry := fmt.Sprintf("%s%s [%s] %s:%d %s%s\n",
-		colorCode, timestamp, level.String(), file, line, message, resetCode)
-	writer.Write([]byte(logEntry))
+// FormatEntry 格式化为彩色文本格式：时间.毫秒 [级别] 文件:行号 消息 参数，
+// 参数的键和值按类型分别着色
+func (f *ColorFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	frame := e.CallerFrame()
+	levelColor := f.palette.level(e.Level)
+	buf := getBuffer()
+	buf.b = append(buf.b, levelColor...)
+	// 时间：默认年月日时分秒.毫秒
+	buf.b = f.time.append(buf.b, e.Time)
+	buf.b = append(buf.b, " ["...)
+	buf.b = append(buf.b, e.Level.String()...)
+	buf.b = append(buf.b, "] "...)
+	buf.b = f.link.appendOpen(buf.b, frame)
+	buf.b = appendCaller(buf.b, frame, f.path)
+	buf.b = f.link.appendClose(buf.b, frame)
+	buf.b = append(buf.b, ' ')
+	if f.raw {
+		buf.b = append(buf.b, e.Message...)
+	} else {
+		buf.b = appendTextEscaped(buf.b, e.Message)
+	}
+	if len(e.Fields) > 0 {
+		buf.b = append(buf.b, ' ')
+		buf.b = appendColorFields(buf.b, f.palette, e.Fields, f.raw)
+	}
+	buf.b = appendReset(buf.b, levelColor)
+	buf.b = append(buf.b, '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendCaller 追加 文件名:行号，path 为 true 时追加相对于仓库根目录的路径，调用位置未知时为 ?:0
+func appendCaller(dst []byte, frame runtime.Frame, path bool) []byte {
+	if frame.File == "" {
+		return append(dst, "?:0"...)
+	}
+	if path {
+		dst = append(dst, sourcePath(frame.File)...)
+	} else {
+		dst = append(dst, filepath.Base(frame.File)...)
+	}
+	dst = append(dst, ':')
+	return strconv.AppendInt(dst, int64(frame.Line), 10)
 }
 
-// getColorCode 根据日志级别获取颜色代码
+// appendColorFields 追加彩色的参数：键和值按配色着色；位置参数中的字符串不着色。
+// raw 为 false 时按 appendEscapedTextFields 的规则转义和加引号
+func appendColorFields(dst []byte, p *palette, fields []Field, raw bool) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		if f.Key == "" && f.Type == StringType {
+			if raw {
+				dst = append(dst, f.str...)
+			} else {
+				dst = appendTextEscaped(dst, f.str)
+			}
+			continue
+		}
+		if f.Key != "" {
+			dst = append(dst, p.key...)
+			if raw {
+				dst = append(dst, f.Key...)
+			} else {
+				dst = appendTextKey(dst, f.Key)
+			}
+			dst = appendReset(dst, p.key)
+			dst = append(dst, '=')
+		}
+		color := p.value(f.Type)
+		dst = append(dst, color...)
+		switch {
+		case raw:
+			dst = appendFieldValue(dst, f)
+		case f.Key == "":
+			dst = appendFieldValueFunc(dst, f, appendTextEscaped)
+		default:
+			dst = appendTextValue(dst, f)
+		}
+		dst = appendReset(dst, color)
+	}
+	return dst
+}
+
+// getColorCode 根据日志级别获取默认配色的颜色代码，未知级别为白色
 func getColorCode(level LogLevel) string {
-	switch level {
-	case DebugLevel:
-		return ColorCyan // 青色
-	case InfoLevel:
-		return ColorGreen // 绿色
-	case WarningLevel:
-		return ColorYellow // 黄色
-	case ErrorLevel:
-		return ColorRed // 红色
-	case PanicLevel:
-		return ColorPurple // 紫色
-	default:
-		return ColorWhite // 白色
+	if color := defaultPalette.level(level); color != "" {
+		return color
 	}
+	return ColorWhite
 }
 
 // getResetCode 获取重置颜色代码
@@ -68,6 +150,8 @@ type ColorAwareMultiWriter struct {
 	consoleWriter io.Writer
 	fileWriter    io.Writer
 	useColor      bool
+	color         *ColorFormatter
+	text          *TextFormatter
 }
 
 // NewColorAwareMultiWriter 创建颜色感知的多重写入器
@@ -76,25 +160,27 @@ func NewColorAwareMultiWriter(consoleWriter, fileWriter io.Writer, useColor bool
 		consoleWriter: consoleWriter,
 		fileWriter:    fileWriter,
 		useColor:      useColor,
+		color:         NewColorFormatter(),
+		text:          NewTextFormatter(),
 	}
 }
 
 // WriteWithFormatter 使用指定的格式化器写入
 func (cw *ColorAwareMultiWriter) WriteWithFormatter(level LogLevel, message string) {
+	e := acquireEntry(level, message, 1)
+	defer releaseEntry(e)
+
 	// 写入控制台（可能带颜色）
 	if cw.consoleWriter != nil {
 		if cw.useColor {
-			colorFormatter := NewColorFormatter()
-			colorFormatter.Format(cw.consoleWriter, level, message)
+			cw.color.FormatEntry(cw.consoleWriter, e)
 		} else {
-			textFormatter := NewTextFormatter()
-			textFormatter.Format(cw.consoleWriter, level, message)
+			cw.text.FormatEntry(cw.consoleWriter, e)
 		}
 	}
 
 	// 写入文件（不带颜色）
 	if cw.fileWriter != nil {
-		textFormatter := NewTextFormatter()
-		textFormatter.Format(cw.fileWriter, level, message)
+		cw.text.FormatEntry(cw.fileWriter, e)
 	}
 }
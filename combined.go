@@ -1,27 +1,57 @@
 package ygggo_log
 
-import "io"
+import (
+	"errors"
+	"io"
+)
 
-// CombinedFormatter 同时将日志写到控制台（彩色）和文件（JSON）
+// CombinedFormatter 同时将日志写到控制台和文件，默认控制台彩色、文件 JSON
 type CombinedFormatter struct {
-	console io.Writer
-	file    io.Writer
+	console          io.Writer
+	file             io.Writer
+	consoleFormatter EntryFormatter
+	fileFormatter    EntryFormatter
 }
 
 func NewCombinedFormatter(console io.Writer, file io.Writer) *CombinedFormatter {
-	return &CombinedFormatter{console: console, file: file}
+	return NewCombinedFormatterWith(console, NewColorFormatter(), file, NewJsonFormatter())
+}
+
+// NewCombinedFormatterWith 使用指定的格式化器分别编码控制台和文件输出
+func NewCombinedFormatterWith(console io.Writer, consoleFormatter EntryFormatter, file io.Writer, fileFormatter EntryFormatter) *CombinedFormatter {
+	return &CombinedFormatter{
+		console:          console,
+		file:             file,
+		consoleFormatter: consoleFormatter,
+		fileFormatter:    fileFormatter,
+	}
 }
 
 func (f *CombinedFormatter) Format(_ io.Writer, level LogLevel, message string) {
-	// 控制台：彩色
+	formatMessage(f, nil, level, message)
+}
+
+// FormatEntry 将同一条 Entry 分别编码后写入控制台和文件，忽略传入的 writer；
+// 输出为 BufferedWriter 时按日志级别决定是否立即刷新
+func (f *CombinedFormatter) FormatEntry(_ io.Writer, e *Entry) {
 	if f.console != nil {
-		cf := NewColorFormatter()
-		cf.Format(f.console, level, message)
+		f.consoleFormatter.FormatEntry(f.console, e)
+		notifyWritten(f.console, e.Level)
 	}
-	// 文件：JSON
 	if f.file != nil {
-		jf := NewJsonFormatter()
-		jf.Format(f.file, level, message)
+		f.fileFormatter.FormatEntry(f.file, e)
+		notifyWritten(f.file, e.Level)
 	}
 }
 
+// Close 关闭控制台和文件输出：AsyncWriter 写完队列中的日志，BufferedWriter 写出缓存并关闭文件，
+// 返回遇到的错误
+func (f *CombinedFormatter) Close() error {
+	var errs []error
+	for _, w := range []io.Writer{f.console, f.file} {
+		if c, ok := w.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
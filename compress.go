@@ -0,0 +1,165 @@
+package ygggo_log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SetCompression enables gzip compression of rotated files at the given level
+// (gzip.BestSpeed to gzip.BestCompression, or gzip.DefaultCompression); level 0
+// disables it. After each rotation <filename>.1 is compressed in the background
+// to <filename>.1.gz, and compressed files keep being counted and shifted like
+// plain ones. Rotated files left uncompressed by a previous process are
+// compressed when compression is enabled, and temporary files left by an
+// interrupted compression are removed.
+func (rw *RotatingWriter) SetCompression(level int) error {
+	if level != 0 && (level < gzip.HuffmanOnly || level > gzip.BestCompression) {
+		return fmt.Errorf("invalid gzip compression level %d", level)
+	}
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	rw.removeStaleTemps()
+	rw.compression = level
+	if level == 0 {
+		return nil
+	}
+
+	// 压缩之前未压缩的轮转文件，目录无法读取时等到下次轮转再处理
+	files, _ := rw.getRotatedFiles()
+	var pending []int
+	for _, f := range files {
+		if f.ext == "" && rw.pendingCompression(f.index) == nil {
+			pending = append(pending, f.index)
+		}
+	}
+	rw.compressAsync(pending...)
+	return nil
+}
+
+// compressJob 一个后台压缩任务。轮转时文件的索引随之增加，
+// 被数量或保留策略删除时标记为 dropped，压缩结果随后丢弃
+type compressJob struct {
+	index   int  // 待压缩文件当前的轮转索引
+	dropped bool // 文件已被删除
+}
+
+// compressAsync 在后台依次压缩指定索引的轮转文件，调用方需持有锁。
+// 压缩期间不持有锁，写入和轮转不会等待压缩完成；压缩失败时保留原文件
+func (rw *RotatingWriter) compressAsync(indices ...int) {
+	if len(indices) == 0 {
+		return
+	}
+	jobs := make([]*compressJob, len(indices))
+	for i, index := range indices {
+		jobs[i] = &compressJob{index: index}
+	}
+	rw.compressJobs = append(rw.compressJobs, jobs...)
+	level := rw.compression
+	rw.compressing.Add(1)
+	go func() {
+		defer rw.compressing.Done()
+		for _, job := range jobs {
+			rw.runCompressJob(job, level)
+		}
+	}()
+}
+
+// runCompressJob 在持有锁时打开待压缩文件、替换压缩结果，其余时间不持有锁
+func (rw *RotatingWriter) runCompressJob(job *compressJob, level int) {
+	rw.mutex.Lock()
+	var src *os.File
+	var err error
+	if !job.dropped {
+		src, err = os.Open(rw.rotatedPath(job.index, ""))
+	}
+	rw.mutex.Unlock()
+
+	var tmp string
+	if src != nil && err == nil {
+		tmp, err = compressToTemp(src, filepath.Dir(rw.filename), filepath.Base(rw.filename), level)
+		_ = src.Close()
+	}
+
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	rw.finishCompressJob(job)
+	if src == nil || err != nil {
+		return
+	}
+	if job.dropped {
+		_ = os.Remove(tmp)
+		return
+	}
+	path := rw.rotatedPath(job.index, "")
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		_ = os.Remove(tmp)
+		return
+	}
+	_ = os.Remove(path)
+}
+
+// pendingCompression 返回指定索引上尚未完成的压缩任务，调用方需持有锁
+func (rw *RotatingWriter) pendingCompression(index int) *compressJob {
+	for _, job := range rw.compressJobs {
+		if job.index == index && !job.dropped {
+			return job
+		}
+	}
+	return nil
+}
+
+// finishCompressJob 从未完成列表中移除压缩任务，调用方需持有锁
+func (rw *RotatingWriter) finishCompressJob(job *compressJob) {
+	rw.compressJobs = slices.DeleteFunc(rw.compressJobs, func(j *compressJob) bool { return j == job })
+}
+
+// removeStaleTemps 删除之前的进程压缩中断时遗留的临时文件，调用方需持有锁。
+// 本写入器仍有压缩任务时跳过，避免删除正在写入的临时文件
+func (rw *RotatingWriter) removeStaleTemps() {
+	if len(rw.compressJobs) > 0 {
+		return
+	}
+	dir := filepath.Dir(rw.filename)
+	prefix := filepath.Base(rw.filename) + "."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".gz.tmp") {
+			_ = os.Remove(filepath.Join(dir, name))
+		}
+	}
+}
+
+// compressToTemp 将 src 压缩到 dir 中以 base 开头的临时文件并返回其路径。
+// 临时文件名不符合轮转文件的命名，不会被计数或移动；失败时删除临时文件
+func compressToTemp(src *os.File, dir, base string, level int) (string, error) {
+	dst, err := os.CreateTemp(dir, base+".*.gz.tmp")
+	if err != nil {
+		return "", err
+	}
+	zw, err := gzip.NewWriterLevel(dst, level)
+	if err == nil {
+		if stat, statErr := src.Stat(); statErr == nil {
+			zw.ModTime = stat.ModTime()
+		}
+		if _, err = io.Copy(zw, src); err == nil {
+			err = zw.Close()
+		}
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
@@ -0,0 +1,275 @@
+package ygggo_log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readGzip 读取 gzip 文件的内容
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open %s failed: %v", path, err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader %s failed: %v", path, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Read %s failed: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestRotatingWriter_Compression(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	rw, err := NewRotatingWriter(filename, 10, 4)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	if err := rw.SetCompression(gzip.BestCompression); err != nil {
+		t.Fatalf("SetCompression failed: %v", err)
+	}
+
+	for _, line := range []string{"first...\n", "second..\n", "third...\n", "fourth..\n", "fifth...\n"} {
+		rw.Write([]byte(line))
+	}
+	rw.Close()
+
+	// 共 4 个文件：当前文件和 3 个压缩后的轮转文件，最早的一个被删除
+	want := map[string]string{"app.log.1.gz": "fourth..\n", "app.log.2.gz": "third...\n", "app.log.3.gz": "second..\n"}
+	for name, content := range want {
+		if got := readGzip(t, filepath.Join(filepath.Dir(filename), name)); got != content {
+			t.Errorf("Expected %q in %s, got: %q", content, name, got)
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Dir(filename))
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 4 {
+		t.Errorf("Expected app.log and 3 compressed files, got: %v", names)
+	}
+}
+
+func TestRotatingWriter_CompressLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	os.WriteFile(filename+".1", []byte("left over\n"), 0666)
+	os.WriteFile(filename+".2.gz.tmp", []byte("partial"), 0666)
+
+	rw, err := NewRotatingWriter(filename, 0, 3)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	rw.SetCompression(gzip.DefaultCompression)
+	rw.Close()
+
+	if got := readGzip(t, filename+".1.gz"); got != "left over\n" {
+		t.Errorf("Unexpected compressed content: %q", got)
+	}
+	if _, err := os.Stat(filename + ".1"); !os.IsNotExist(err) {
+		t.Errorf("Expected the uncompressed file to be removed, got: %v", err)
+	}
+	files, _ := rw.getRotatedFiles()
+	if len(files) != 1 || files[0].index != 1 || files[0].ext != ".gz" {
+		t.Errorf("Expected only the compressed file to be counted, got: %+v", files)
+	}
+}
+
+func TestRotatingWriter_RemovesStaleTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	os.WriteFile(filename+".123456.gz.tmp", []byte("partial"), 0666)
+	os.WriteFile(filepath.Join(dir, "other.log.123456.gz.tmp"), []byte("partial"), 0666)
+
+	rw, err := NewRotatingWriter(filename, 0, 3)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	rw.SetCompression(0)
+	rw.Close()
+
+	// 只删除属于本文件的临时文件，即使未开启压缩
+	if names := listDir(t, dir); len(names) != 2 || names[0] != "app.log" || names[1] != "other.log.123456.gz.tmp" {
+		t.Errorf("Expected the stale temp file of app.log to be removed, got: %v", names)
+	}
+}
+
+func TestRotatingWriter_CompressionInvalidLevel(t *testing.T) {
+	rw, err := NewRotatingWriter(filepath.Join(t.TempDir(), "app.log"), 0, 3)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	defer rw.Close()
+	if err := rw.SetCompression(10); err == nil || !strings.Contains(err.Error(), "level") {
+		t.Errorf("Expected an invalid level error, got: %v", err)
+	}
+}
+
+func TestParseCompress(t *testing.T) {
+	testCases := map[string]int{
+		"":      0,
+		"false": 0,
+		"true":  gzip.DefaultCompression,
+		"on":    gzip.DefaultCompression,
+		"9":     gzip.BestCompression,
+		"1":     gzip.BestSpeed,
+		"ON":    gzip.DefaultCompression,
+	}
+	for input, want := range testCases {
+		if got, ok := parseCompress(input); !ok || got != want {
+			t.Errorf("parseCompress(%q): expected %d, got: %d (ok=%v)", input, want, got, ok)
+		}
+	}
+	for _, input := range []string{"12", "-1", "gzip", "ture"} {
+		if got, ok := parseCompress(input); ok || got != 0 {
+			t.Errorf("parseCompress(%q): expected an invalid setting, got: %d (ok=%v)", input, got, ok)
+		}
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidCompress(t *testing.T) {
+	t.Setenv("YGGGO_LOG_COMPRESS", "12")
+
+	config := LoadConfigFromEnv()
+	if config.Compress != 0 {
+		t.Errorf("Expected an invalid setting to disable compression, got: %d", config.Compress)
+	}
+	if len(config.Errors) != 1 || !strings.Contains(config.Errors[0].Error(), "YGGGO_LOG_COMPRESS") {
+		t.Errorf("Expected a config error, got: %v", config.Errors)
+	}
+}
+
+func TestNewLoggerFromConfig_InvalidCompressionLevel(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := LoadConfigFromEnv()
+	config.OutputFile = filename
+	config.Compress = 12
+	logger := NewLoggerFromConfig(config)
+	logger.Close()
+
+	data, _ := os.ReadFile(filename)
+	if !strings.Contains(string(data), "ignored invalid logging configuration") || !strings.Contains(string(data), "invalid gzip compression level 12") {
+		t.Errorf("Expected the invalid compression level to be reported, got: %q", data)
+	}
+}
+
+// pendingJob 模拟一个仍在进行的压缩任务：注册任务并占用 compressing，但不启动后台压缩
+func pendingJob(rw *RotatingWriter, index int) *compressJob {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	job := &compressJob{index: index}
+	rw.compressJobs = append(rw.compressJobs, job)
+	rw.compressing.Add(1)
+	return job
+}
+
+func TestRotatingWriter_RotationDoesNotWaitForCompression(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	os.WriteFile(filename+".1", []byte("old.....\n"), 0666)
+	rw, err := NewRotatingWriter(filename, 10, 5)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	rw.compression = gzip.BestSpeed
+	job := pendingJob(rw, 1)
+
+	done := make(chan struct{})
+	go func() {
+		rw.Write([]byte("first...\n"))
+		rw.Write([]byte("second..\n")) // 轮转：.1 -> .2，当前文件 -> .1
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Write blocked on an unfinished compression")
+	}
+	if job.index != 2 || job.dropped {
+		t.Errorf("Expected the pending job to follow its file to index 2, got: %+v", *job)
+	}
+
+	rw.runCompressJob(job, gzip.BestSpeed)
+	rw.compressing.Done()
+	rw.Close()
+
+	want := map[string]string{"app.log.1.gz": "first...\n", "app.log.2.gz": "old.....\n"}
+	for name, content := range want {
+		if got := readGzip(t, filepath.Join(dir, name)); got != content {
+			t.Errorf("Expected %q in %s, got: %q", content, name, got)
+		}
+	}
+	if names := listDir(t, dir); len(names) != 3 {
+		t.Errorf("Expected app.log and two compressed files, got: %v", names)
+	}
+}
+
+func TestRotatingWriter_CompressionOfDeletedFileDiscarded(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	os.WriteFile(filename+".1", []byte("old.....\n"), 0666)
+	rw, err := NewRotatingWriter(filename, 10, 2)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	job := pendingJob(rw, 1)
+
+	rw.Write([]byte("first...\n"))
+	rw.Write([]byte("second..\n")) // 最多 2 个文件：.1 被删除
+	if !job.dropped {
+		t.Errorf("Expected the job of a deleted file to be dropped, got: %+v", *job)
+	}
+	rw.runCompressJob(job, gzip.BestSpeed)
+	rw.compressing.Done()
+	rw.Close()
+
+	if names := listDir(t, dir); len(names) != 2 || names[1] != "app.log.1" {
+		t.Errorf("Expected app.log and the new app.log.1 only, got: %v", names)
+	}
+	if data, _ := os.ReadFile(filename + ".1"); string(data) != "first...\n" {
+		t.Errorf("Expected app.log.1 to hold the newest rotation, got: %q", data)
+	}
+}
+
+func TestRotatingWriter_CompressionUnderLoad(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	rw, err := NewRotatingWriter(filename, 64<<10, 6)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	rw.SetCompression(gzip.BestCompression)
+
+	line := []byte(strings.Repeat("payload ", 1023) + "\n") // 8KB
+	for gen := 0; gen < 20; gen++ {
+		for i := 0; i < 8; i++ {
+			rw.Write(line)
+		}
+	}
+	rw.Close()
+
+	names := listDir(t, dir)
+	if len(names) != 6 || names[0] != "app.log" {
+		t.Fatalf("Expected app.log and 5 rotated files, got: %v", names)
+	}
+	for _, name := range names[1:] {
+		if !strings.HasSuffix(name, ".gz") {
+			t.Errorf("Expected every rotated file to be compressed, got: %s", name)
+			continue
+		}
+		if got := readGzip(t, filepath.Join(dir, name)); len(got) != 8*len(line) {
+			t.Errorf("Unexpected size of %s: %d", name, len(got))
+		}
+	}
+}
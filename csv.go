@@ -0,0 +1,232 @@
+package ygggo_log
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// CSV 列名中的内置列，其他列名按参数键名取值
+const (
+	ColumnTime    = "time"    // 记录时间
+	ColumnLevel   = "level"   // 日志级别
+	ColumnCaller  = "caller"  // 调用位置 文件名:行号
+	ColumnMessage = "message" // 日志消息
+)
+
+// DefaultCSVColumns 未通过 WithColumns 配置时使用的列
+var DefaultCSVColumns = []string{ColumnTime, ColumnLevel, ColumnCaller, ColumnMessage}
+
+// WithColumns 设置 CSV/TSV 格式化器按顺序输出的列：time、level、caller、message 为内置列，
+// 其他列名取同名参数的值（没有该参数时取同名常量字段），都没有时输出空单元格
+func WithColumns(columns ...string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.columns = append([]string(nil), columns...)
+	}
+}
+
+// WithHeader 让 CSV/TSV 格式化器在文件开头输出列名行；写入 RotatingWriter 时每个新文件都会输出
+func WithHeader() FormatterOption {
+	return func(o *formatterOptions) {
+		o.header = true
+	}
+}
+
+// csvColumn 解析后的一列
+type csvColumn struct {
+	name   string
+	static *Field // 同名常量字段，参数中没有该键时使用
+}
+
+// CSVFormatter 按声明的列输出 CSV（RFC 4180）或 TSV，便于直接导入表格和 BI 工具：
+//
+//	time,level,caller,message,user
+//	2025-01-02 15:04:05.000,INFO,main.go:12,user login,tom
+//
+// 包含分隔符、双引号、换行或以空白开头的单元格会加上双引号，内部的双引号写成两个。
+// CSV 每行以 CRLF 结尾，TSV 以 LF 结尾。
+//
+// 启用 WithHeader 后，格式化器的首条日志前会输出列名行（输出为已有内容的文件时跳过），
+// 写入 RotatingWriter 时每次轮转后的新文件也以列名行开头。
+// 因此启用列名行时格式化器会串行化自己的写入，同一个输出不应再与其他格式化器共享。
+type CSVFormatter struct {
+	comma   byte
+	newline string
+	time    timeEncoder
+	columns []csvColumn
+	header  []byte // 预先编码的列名行，nil 表示不输出
+
+	mu      sync.Mutex
+	started bool // 是否已写过日志
+}
+
+// NewCSVFormatter 创建 CSV 格式化器，默认列为 DefaultCSVColumns，默认时间布局为带毫秒的
+// 2006-01-02 15:04:05
+func NewCSVFormatter(opts ...FormatterOption) *CSVFormatter {
+	return newCSVFormatter(',', "\r\n", opts)
+}
+
+// NewTSVFormatter 创建以制表符分隔的 TSV 格式化器，配置与 NewCSVFormatter 相同
+func NewTSVFormatter(opts ...FormatterOption) *CSVFormatter {
+	return newCSVFormatter('\t', "\n", opts)
+}
+
+func newCSVFormatter(comma byte, newline string, opts []FormatterOption) *CSVFormatter {
+	o := newFormatterOptions(opts)
+	names := o.columns
+	if len(names) == 0 {
+		names = DefaultCSVColumns
+	}
+
+	f := &CSVFormatter{
+		comma:   comma,
+		newline: newline,
+		time:    newTimeEncoder(o, "2006-01-02 15:04:05.000"),
+	}
+	for _, name := range names {
+		col := csvColumn{name: name}
+		for i := range o.staticFields {
+			if o.staticFields[i].Key == name {
+				col.static = &o.staticFields[i]
+				break
+			}
+		}
+		f.columns = append(f.columns, col)
+	}
+	if o.header {
+		for i, col := range f.columns {
+			if i > 0 {
+				f.header = append(f.header, comma)
+			}
+			f.header = f.appendCell(f.header, col.name)
+		}
+		f.header = append(f.header, newline...)
+	}
+	return f
+}
+
+// Format 格式化为 CSV/TSV 行
+func (f *CSVFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为 CSV/TSV 行
+func (f *CSVFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	if f.header == nil {
+		buf.b = f.appendRow(buf.b, e)
+		writer.Write(buf.b)
+		putBuffer(buf)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf.b = f.appendRow(buf.b, e)
+	header := !f.started && outputEmpty(writer)
+	if rw, ok := writer.(interface{ WillRotate(n int) bool }); ok && !header {
+		header = rw.WillRotate(len(buf.b))
+	}
+	if header {
+		// 列名行与首条记录一起写入，轮转时二者落在同一个新文件中
+		row := getBuffer()
+		row.b = append(row.b, f.header...)
+		row.b = append(row.b, buf.b...)
+		putBuffer(buf)
+		buf = row
+	}
+	f.started = true
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendRow 追加一行
+func (f *CSVFormatter) appendRow(dst []byte, e *Entry) []byte {
+	for i, col := range f.columns {
+		if i > 0 {
+			dst = append(dst, f.comma)
+		}
+		start := len(dst)
+		switch col.name {
+		case ColumnTime:
+			dst = f.time.append(dst, e.Time)
+		case ColumnLevel:
+			dst = append(dst, e.Level.String()...)
+		case ColumnCaller:
+			file, line := e.Caller()
+			dst = append(dst, filepath.Base(file)...)
+			dst = append(dst, ':')
+			dst = strconv.AppendInt(dst, int64(line), 10)
+		case ColumnMessage:
+			dst = append(dst, e.Message...)
+		default:
+			if field, ok := lookupField(e.Fields, col.name); ok {
+				dst = appendFieldValue(dst, field)
+			} else if col.static != nil {
+				dst = appendFieldValue(dst, *col.static)
+			}
+		}
+		dst = f.quoteTail(dst, start)
+	}
+	return append(dst, f.newline...)
+}
+
+// appendCell 追加一个单元格，需要时加引号
+func (f *CSVFormatter) appendCell(dst []byte, s string) []byte {
+	start := len(dst)
+	dst = append(dst, s...)
+	return f.quoteTail(dst, start)
+}
+
+// quoteTail 按 RFC 4180 为 dst[start:] 中的单元格加引号：包含分隔符、双引号、回车换行
+// 或以空白开头时用双引号包围，内部的双引号写成两个
+func (f *CSVFormatter) quoteTail(dst []byte, start int) []byte {
+	cell := dst[start:]
+	quote := len(cell) > 0 && (cell[0] == ' ' || cell[0] == '\t')
+	for _, c := range cell {
+		if c == f.comma || c == '"' || c == '\r' || c == '\n' {
+			quote = true
+			break
+		}
+	}
+	if !quote {
+		return dst
+	}
+	s := string(cell)
+	dst = append(dst[:start], '"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			dst = append(dst, '"')
+		}
+		dst = append(dst, s[i])
+	}
+	return append(dst, '"')
+}
+
+// lookupField 返回第一个键名为 key 的参数
+func lookupField(fields []Field, key string) (Field, bool) {
+	for _, f := range fields {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// outputEmpty 判断输出是否还没有内容：RotatingWriter 看当前文件大小，*os.File 看文件大小，
+// 其他输出视为空
+func outputEmpty(w io.Writer) bool {
+	switch out := w.(type) {
+	case *RotatingWriter:
+		return out.Size() == 0
+	case *BufferedWriter:
+		return out.buffered() == 0 && outputEmpty(out.w)
+	case *os.File:
+		info, err := out.Stat()
+		return err != nil || !info.Mode().IsRegular() || info.Size() == 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,120 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCSVFormatter_Columns(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewCSVFormatter(
+		WithColumns("time", "level", "message", "user", "missing", "env"),
+		WithStaticFields(String("env", "prod")),
+	))
+
+	logger.Info("user login", "user=tom")
+
+	want := "2025-06-07 08:09:10.123,INFO,user login,tom,,prod\r\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got: %q", want, buf.String())
+	}
+}
+
+func TestCSVFormatter_Quoting(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewCSVFormatter(WithColumns("message", "note", "error")))
+
+	logger.Log(InfoLevel, "a, \"quoted\"\nline", String("note", " padded"), Err(errors.New("x,y")))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	want := []string{"a, \"quoted\"\nline", " padded", "x,y"}
+	if len(records) != 1 || strings.Join(records[0], "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got: %q", want, records)
+	}
+}
+
+func TestTSVFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewTSVFormatter(WithColumns("level", "message", "path"), WithHeader()))
+
+	logger.Warning("slow", String("path", "/a\tb"))
+	logger.Info("ok", "path=/c")
+
+	want := "level\tmessage\tpath\nWARNING\tslow\t\"/a\tb\"\nINFO\tok\t/c\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got: %q", want, buf.String())
+	}
+}
+
+func TestCSVFormatter_HeaderSkippedForExistingFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.csv")
+	for i := 0; i < 2; i++ {
+		file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			t.Fatalf("Failed to open file: %v", err)
+		}
+		logger := NewLogger(file)
+		logger.SetFormatter(NewCSVFormatter(WithColumns("message"), WithHeader()))
+		logger.Info("run")
+		file.Close()
+	}
+
+	data, _ := os.ReadFile(filename)
+	if string(data) != "message\r\nrun\r\nrun\r\n" {
+		t.Errorf("Expected a single header, got: %q", data)
+	}
+}
+
+func TestCSVFormatter_HeaderOnRotation(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.csv")
+	rw, err := NewRotatingWriter(filename, 60, 5)
+	if err != nil {
+		t.Fatalf("Failed to create rotating writer: %v", err)
+	}
+	defer rw.Close()
+
+	logger := NewLogger(rw)
+	logger.SetFormatter(NewCSVFormatter(WithColumns("level", "message", "i"), WithHeader()))
+	for i := 0; i < 10; i++ {
+		logger.Info("rotation test", Int("i", i))
+	}
+
+	files, _ := filepath.Glob(filename + "*")
+	if len(files) < 2 {
+		t.Fatalf("Expected rotation to produce several files, got: %v", files)
+	}
+	for _, name := range files {
+		data, _ := os.ReadFile(name)
+		if !strings.HasPrefix(string(data), "level,message,i\r\n") {
+			t.Errorf("Expected %s to start with the header, got: %q", filepath.Base(name), data)
+		}
+		if strings.Count(string(data), "level,message,i") != 1 {
+			t.Errorf("Expected one header in %s, got: %q", filepath.Base(name), data)
+		}
+	}
+}
+
+func TestLoadConfigFromEnv_Columns(t *testing.T) {
+	os.Setenv("YGGGO_LOG_COLUMNS", " time, level ,,user")
+	defer os.Unsetenv("YGGGO_LOG_COLUMNS")
+
+	config := LoadConfigFromEnv()
+	if strings.Join(config.Columns, ",") != "time,level,user" {
+		t.Errorf("Unexpected columns: %q", config.Columns)
+	}
+	if parseLogFormat("tsv") != TSVFormat || CSVFormat.String() != "csv" {
+		t.Errorf("Expected csv/tsv formats to be recognized")
+	}
+}
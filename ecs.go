@@ -0,0 +1,161 @@
+package ygggo_log
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ECSVersion ECSFormatter 输出的 ecs.version
+const ECSVersion = "8.11.0"
+
+// ECSFormatter 输出符合 Elastic Common Schema 的 JSON 行，可直接写入 Elasticsearch：
+//
+//	{"@timestamp":"...","log.level":"info","message":"...","ecs.version":"8.11.0",
+//	 "log":{"origin":{"file":{"name":"main.go","line":12}}},
+//	 "service":{"name":"api"},"host":{"hostname":"web-1"},"error":{...},"user":"tom"}
+//
+// 第一个键为 error 或无键名的错误参数映射到 error.message 和 error.type，
+// 其余参数作为顶层字段输出，位置参数依次命名为 arg0、arg1……；键名与 ECS 内置字段
+// 相同或位于其下的参数（如 message、service、log.level）加 labels. 前缀，不会覆盖内置字段。
+// 常量字段 service、env（或 environment）、version 分别映射到 service.name、
+// service.environment 和 service.version，其余与 ECS 内置字段同名的常量字段加 labels. 前缀
+type ECSFormatter struct {
+	time        timeEncoder
+	service     string
+	environment string
+	version     string
+	hostname    string
+	static      []byte // 预先编码的常量字段
+}
+
+// NewECSFormatter 创建 ECS 格式化器；时间默认使用 UTC 和带毫秒的 ISO8601，
+// 服务名通过 WithServiceName 或常量字段 service 设置，默认取可执行文件名
+func NewECSFormatter(opts ...FormatterOption) *ECSFormatter {
+	o := newFormatterOptions(opts)
+	if o.timeLocation == nil {
+		o.timeLocation = time.UTC
+	}
+	name, static := takeStaticField(o.staticFields, "service")
+	if o.serviceName == "" {
+		o.serviceName = name
+	}
+	environment, static := takeStaticField(static, "env", "environment")
+	version, static := takeStaticField(static, "version")
+	return &ECSFormatter{
+		time:        newTimeEncoder(o, "2006-01-02T15:04:05.000Z07:00"),
+		service:     o.service(),
+		environment: environment,
+		version:     version,
+		hostname:    hostname(),
+		static:      appendECSFields(nil, static, -1),
+	}
+}
+
+// Format 格式化为 ECS JSON
+func (f *ECSFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为 ECS JSON
+func (f *ECSFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	buf.b = append(buf.b, `{"@timestamp":`...)
+	buf.b = f.time.appendJSON(buf.b, e.Time)
+	buf.b = append(buf.b, `,"log.level":"`...)
+	buf.b = appendLowerASCII(buf.b, e.Level.String())
+	buf.b = append(buf.b, `","message":`...)
+	buf.b = appendJSONString(buf.b, e.Message)
+	buf.b = append(buf.b, `,"ecs.version":"`+ECSVersion+`"`...)
+
+	file, line := e.Caller()
+	buf.b = append(buf.b, `,"log":{"origin":{"file":{"name":`...)
+	buf.b = appendJSONString(buf.b, filepath.Base(file))
+	buf.b = append(buf.b, `,"line":`...)
+	buf.b = strconv.AppendInt(buf.b, int64(line), 10)
+	buf.b = append(buf.b, `}}}`...)
+
+	buf.b = append(buf.b, `,"service":{"name":`...)
+	buf.b = appendJSONString(buf.b, f.service)
+	if f.environment != "" {
+		buf.b = append(buf.b, `,"environment":`...)
+		buf.b = appendJSONString(buf.b, f.environment)
+	}
+	if f.version != "" {
+		buf.b = append(buf.b, `,"version":`...)
+		buf.b = appendJSONString(buf.b, f.version)
+	}
+	buf.b = append(buf.b, '}')
+	if f.hostname != "" {
+		buf.b = append(buf.b, `,"host":{"hostname":`...)
+		buf.b = appendJSONString(buf.b, f.hostname)
+		buf.b = append(buf.b, '}')
+	}
+
+	buf.b = append(buf.b, f.static...)
+
+	errIndex := errorFieldIndex(e.Fields)
+	if errIndex >= 0 {
+		buf.b = appendECSError(buf.b, e.Fields[errIndex])
+	}
+	buf.b = appendECSFields(buf.b, e.Fields, errIndex)
+	buf.b = append(buf.b, '}', '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// ecsReservedKeys ECSFormatter 自身输出的顶层字段
+var ecsReservedKeys = []string{"@timestamp", "message", "ecs", "log", "service", "host", "error", "labels"}
+
+// ecsReserved 判断键名是否与 ECS 内置字段相同，或位于其下（如 log.level、service.name）
+func ecsReserved(key string) bool {
+	for _, reserved := range ecsReservedKeys {
+		if key == reserved || (strings.HasPrefix(key, reserved) && key[len(reserved)] == '.') {
+			return true
+		}
+	}
+	return false
+}
+
+// appendECSFields 以顶层字段追加参数，跳过下标为 skip 的参数；与内置字段同名的键加 labels. 前缀
+func appendECSFields(dst []byte, fields []Field, skip int) []byte {
+	positional := 0
+	for i, field := range fields {
+		if i == skip {
+			continue
+		}
+		dst = append(dst, ',', '"')
+		if ecsReserved(field.Key) {
+			dst = append(dst, "labels."...)
+		}
+		dst = appendFieldKey(dst, field, &positional, appendJSONEscaped)
+		dst = append(dst, '"', ':')
+		dst = appendJSONFieldValue(dst, field)
+	}
+	return dst
+}
+
+// errorFieldIndex 返回映射到 error.* 的参数下标（第一个键为 error 或无键名的错误），没有时返回 -1
+func errorFieldIndex(fields []Field) int {
+	for i, f := range fields {
+		if f.Type == ErrorType && (f.Key == "" || f.Key == "error") {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendECSError 追加 ECS 的 error 对象
+func appendECSError(dst []byte, f Field) []byte {
+	dst = append(dst, `,"error":{"message":"`...)
+	dst = appendFieldValueFunc(dst, f, appendJSONEscaped)
+	dst = append(dst, '"')
+	if f.iface != nil {
+		dst = append(dst, `,"type":`...)
+		dst = appendJSONString(dst, fmt.Sprintf("%T", f.iface))
+	}
+	return append(dst, '}')
+}
@@ -0,0 +1,168 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// ecsEntry 用于解析 ECSFormatter 输出的结构
+type ecsEntry struct {
+	Timestamp  string `json:"@timestamp"`
+	Level      string `json:"log.level"`
+	Message    string `json:"message"`
+	ECSVersion string `json:"ecs.version"`
+	Log        struct {
+		Origin struct {
+			File struct {
+				Name string `json:"name"`
+				Line int    `json:"line"`
+			} `json:"file"`
+		} `json:"origin"`
+	} `json:"log"`
+	Service struct {
+		Name string `json:"name"`
+	} `json:"service"`
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+	User string  `json:"user"`
+	Arg0 float64 `json:"arg0"`
+}
+
+func TestECSFormatter_Document(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewECSFormatter(WithServiceName("api")))
+
+	logger.Error("save failed", errors.New("disk full"), "user=tom", 3.5)
+
+	var entry ecsEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if entry.Timestamp != "2025-06-07T00:09:10.123Z" {
+		t.Errorf("Expected UTC millisecond timestamp, got: %s", entry.Timestamp)
+	}
+	if entry.Level != "error" || entry.Message != "save failed" || entry.ECSVersion != ECSVersion {
+		t.Errorf("Unexpected base fields: %+v", entry)
+	}
+	if entry.Log.Origin.File.Name != "ecs_test.go" || entry.Log.Origin.File.Line == 0 {
+		t.Errorf("Unexpected log.origin: %+v", entry.Log.Origin)
+	}
+	if entry.Service.Name != "api" {
+		t.Errorf("Expected service.name api, got: %s", entry.Service.Name)
+	}
+	if entry.Error.Message != "disk full" || entry.Error.Type != "*errors.errorString" {
+		t.Errorf("Unexpected error object: %+v", entry.Error)
+	}
+	if entry.User != "tom" || entry.Arg0 != 3.5 {
+		t.Errorf("Unexpected custom fields: user=%q arg0=%v", entry.User, entry.Arg0)
+	}
+}
+
+func TestECSFormatter_StaticFieldsDoNotCollide(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewECSFormatter(WithStaticFields(
+		String("service", "api"), String("env", "prod"), String("version", "1.2.0"),
+		String("message", "static"), String("log.level", "x"), String("team", "core"))))
+	logger.Info("started")
+
+	out := buf.String()
+	for _, key := range []string{`"service":`, `"message":`, `"log.level":`} {
+		if n := strings.Count(out, key); n != 1 {
+			t.Errorf("Expected %s exactly once, got %d in: %s", key, n, out)
+		}
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+	service, _ := doc["service"].(map[string]any)
+	if service["name"] != "api" || service["environment"] != "prod" || service["version"] != "1.2.0" {
+		t.Errorf("Expected service, env and version under service, got: %v", doc["service"])
+	}
+	if doc["message"] != "started" || doc["log.level"] != "info" {
+		t.Errorf("Expected built-in fields to win, got: %v", doc)
+	}
+	if doc["labels.message"] != "static" || doc["labels.log.level"] != "x" || doc["team"] != "core" {
+		t.Errorf("Expected colliding static fields under labels., got: %v", doc)
+	}
+}
+
+func TestECSFormatter_FieldsDoNotCollide(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewECSFormatter(WithServiceName("api")))
+	logger.Info("started", errors.New("disk full"), String("message", "shadow"), String("@timestamp", "x"), String("service", "billing"),
+		Int("log.origin.file.line", 1), String("error", "none"), String("host.ip", "10.0.0.1"), String("logger", "main"))
+
+	out := buf.String()
+	for _, key := range []string{`,"message":`, `"@timestamp":`, `"service":`, `"error":`} {
+		if n := strings.Count(out, key); n != 1 {
+			t.Errorf("Expected %s exactly once, got %d in: %s", key, n, out)
+		}
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+	if doc["message"] != "started" || doc["service"].(map[string]any)["name"] != "api" || doc["error"].(map[string]any)["message"] != "disk full" {
+		t.Errorf("Expected built-in fields to win, got: %v", doc)
+	}
+	expected := map[string]any{
+		"labels.message":              "shadow",
+		"labels.@timestamp":           "x",
+		"labels.service":              "billing",
+		"labels.log.origin.file.line": float64(1),
+		"labels.error":                "none",
+		"labels.host.ip":              "10.0.0.1",
+		"logger":                      "main",
+	}
+	for key, value := range expected {
+		if doc[key] != value {
+			t.Errorf("Expected %s=%v, got: %v", key, value, doc[key])
+		}
+	}
+}
+
+func TestECSFormatter_NoErrorField(t *testing.T) {
+	var buf bytes.Buffer
+	NewECSFormatter().Format(&buf, InfoLevel, "plain")
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if _, ok := doc["error"]; ok {
+		t.Errorf("Expected no error object, got: %v", doc["error"])
+	}
+	if service, ok := doc["service"].(map[string]any); !ok || service["name"] == "" {
+		t.Errorf("Expected default service name, got: %v", doc["service"])
+	}
+}
+
+func TestLoadConfigFromEnv_ECSFormat(t *testing.T) {
+	os.Setenv("YGGGO_LOG_FORMAT", "ecs")
+	os.Setenv("YGGGO_LOG_SERVICE_NAME", "billing")
+	defer os.Unsetenv("YGGGO_LOG_FORMAT")
+	defer os.Unsetenv("YGGGO_LOG_SERVICE_NAME")
+
+	var buf bytes.Buffer
+	logger := NewLoggerFromEnvWithOutput(&buf)
+	logger.Warning("from env")
+
+	var entry ecsEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if entry.Level != "warning" || entry.Service.Name != "billing" {
+		t.Errorf("Expected ECS output configured from env, got: %+v", entry)
+	}
+}
@@ -0,0 +1,58 @@
+package ygggo_log
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Entry 是一条日志记录的结构化表示，由 Logger 创建并交给格式化器编码。
+// Entry 来自对象池，格式化器不得在 FormatEntry 返回后继续持有它或其中的 Fields。
+type Entry struct {
+	Time    time.Time // 记录时间
+	Level   LogLevel  // 日志级别
+	Message string    // 日志消息（不含参数）
+	Fields  []Field   // 日志参数，按调用时的顺序排列
+
+	pc       uintptr // 调用方的程序计数器，0 表示未知
+	file     string  // 通过 SetCaller 设置的调用位置，pc 为 0 时使用
+	line     int
+	function string
+}
+
+var entryPool = sync.Pool{
+	New: func() any {
+		return &Entry{Fields: make([]Field, 0, 16)}
+	},
+}
+
+// maxPooledFields 超过该容量的 Fields 不再放回对象池，避免偶发的大日志长期占用内存
+const maxPooledFields = 256
+
+// acquireEntry 从对象池取出一条 Entry 并记录当前时间和调用位置。
+// skip 为 0 时表示 acquireEntry 的直接调用方，1 表示再上一层，以此类推。
+func acquireEntry(level LogLevel, message string, skip int) *Entry {
+	e := entryPool.Get().(*Entry)
+	e.Time = time.Now()
+	e.Level = level
+	e.Message = message
+	var pcs [1]uintptr
+	e.file, e.line, e.function = "", 0, ""
+	if runtime.Callers(skip+2, pcs[:]) == 1 {
+		e.pc = pcs[0]
+	} else {
+		e.pc = 0
+	}
+	return e
+}
+
+// releaseEntry 清理 Entry 中的引用后放回对象池
+func releaseEntry(e *Entry) {
+	if cap(e.Fields) > maxPooledFields {
+		return
+	}
+	clear(e.Fields)
+	e.Fields = e.Fields[:0]
+	e.Message = ""
+	entryPool.Put(e)
+}
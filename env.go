@@ -1,8 +1,12 @@
 package ygggo_log
 
 import (
+	"compress/gzip"
+	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,13 +16,84 @@ import (
 // LogConfig holds logger configuration values loaded from environment variables.
 // See LoadConfigFromEnv for defaults and supported variables.
 type LogConfig struct {
-	Level      LogLevel  // minimum log level
-	OutputFile string    // output file path; empty means stdout only
-	Format     LogFormat // text or json for non-colored outputs
-	Console    bool      // force console output
-	Color      bool      // color output for console
-	FileSize   int64     // max file size in bytes (rotation)
-	FileNum    int       // max number of files (rotation)
+	Level       LogLevel  // minimum log level
+	OutputFile  string    // output file path; empty means stdout only
+	Format      LogFormat // text, json, logfmt, ecs, gcp, datadog, cloudwatch, gelf, syslog, otlp, binary, csv, tsv or pretty (colored dev console)
+	Console     bool      // force console output
+	Color       bool      // force color output for console (ColorAlways)
+	ColorMode   ColorMode // console colors: auto (NO_COLOR, FORCE_COLOR, TTY), always or never
+	Theme       string    // console color theme name, see ThemeByName; empty uses DefaultTheme
+	CallerLink  string    // console caller hyperlink template, see WithCallerLink; empty disables links
+	CallerPath  bool      // show the console caller as a repository-relative path, see WithCallerPath
+	FileSize    int64     // max file size in bytes (rotation)
+	FileNum     int       // max number of files (rotation)
+	Compress    int       // gzip level for rotated files (1-9 or -1 for the default level); 0 disables compression
+	Rotate      string    // time-based rotation: hourly, daily, weekly or a cron expression, see ParseSchedule; empty disables it
+	Pattern     string    // console text layout pattern; empty uses the built-in layout
+	FilePattern string    // file text layout pattern; empty keeps JSON in the file
+
+	MaxAge    time.Duration // remove rotated files older than this; 0 keeps them
+	TotalSize int64         // max bytes of rotated files; 0 means unlimited
+
+	FileBuffer    int           // bytes of file output batched in memory; 0 writes each record directly
+	FlushInterval time.Duration // max time a buffered record waits before it is written
+	FlushLevel    LogLevel      // buffered records are written at once after an entry at or above this level
+
+	TimeLayout    string         // timestamp layout or EpochLayout; empty uses each formatter's default
+	TimePrecision TimePrecision  // fractional-second precision (or epoch unit)
+	TimeLocation  *time.Location // timezone for timestamps; nil keeps local time
+
+	ServiceName  string  // service name for formats that carry one; empty uses the executable name
+	StaticFields []Field // constant fields added to every entry by structured formats
+
+	TimeKey        string // JSON key of the timestamp; empty uses timestamp
+	LevelKey       string // JSON key of the level; empty uses level
+	MessageKey     string // JSON key of the message; empty uses message
+	LowercaseLevel bool   // write JSON level names in lower case
+	InlineFields   bool   // write JSON fields as top-level keys instead of inside the message
+	FieldsKey      string // nest JSON fields under this key; takes precedence over InlineFields
+
+	ProjectID        string   // GCP project ID for trace links; empty uses GOOGLE_CLOUD_PROJECT
+	MetricNamespace  string   // CloudWatch Embedded Metric Format namespace; empty disables metrics
+	MetricDimensions []string // CloudWatch metric dimension keys; empty uses service
+
+	Columns []string // CSV/TSV columns; empty uses DefaultCSVColumns
+
+	Journald bool // send console output to systemd-journald when its socket is available
+
+	Limits Limits // message, field and entry size limits applied before formatting
+
+	Errors []error // invalid settings that were ignored; NewLoggerFromConfig logs them as warnings
+}
+
+// formatterOptions converts the timestamp, service, static field, JSON key and
+// column settings into formatter options. CSV/TSV output always starts with a header row.
+func (c *LogConfig) formatterOptions() []FormatterOption {
+	opts := []FormatterOption{
+		WithTimeLayout(c.TimeLayout),
+		WithTimePrecision(c.TimePrecision),
+		WithTimeLocation(c.TimeLocation),
+		WithServiceName(c.ServiceName),
+		WithStaticFields(c.StaticFields...),
+		WithTimeKey(c.TimeKey),
+		WithLevelKey(c.LevelKey),
+		WithMessageKey(c.MessageKey),
+		WithProjectID(c.ProjectID),
+		WithMetricNamespace(c.MetricNamespace),
+		WithMetricDimensions(c.MetricDimensions...),
+		WithColumns(c.Columns...),
+		WithHeader(),
+	}
+	if c.LowercaseLevel {
+		opts = append(opts, WithLowercaseLevel())
+	}
+	if c.InlineFields {
+		opts = append(opts, WithInlineFields())
+	}
+	if c.FieldsKey != "" {
+		opts = append(opts, WithFieldsKey(c.FieldsKey))
+	}
+	return opts
 }
 
 // LoadConfigFromEnv loads configuration from environment variables, applying
@@ -28,9 +103,29 @@ type LogConfig struct {
 //   - OutputFile: "" (stdout only; conventions may choose a default file path)
 //   - Format: text
 //   - Console: false
-//   - Color: false
+//   - Color: false, ColorMode: auto (colors only when stdout is a terminal)
+//   - Theme: "" (DefaultTheme)
+//   - CallerLink: "" (no hyperlinks)
+//   - CallerPath: false (caller shown as file name)
 //   - FileSize: 100MB
 //   - FileNum: 3
+//   - Rotate: "" (size-based rotation only)
+//   - Compress: 0 (rotated files are not compressed)
+//   - MaxAge/TotalSize: 0 (rotated files limited by FileNum only)
+//   - FileBuffer: 0 (unbuffered), FlushInterval: 1s, FlushLevel: ERROR
+//   - Pattern: "" (built-in text layout)
+//   - FilePattern: "" (JSON file)
+//   - TimeLayout/TimePrecision/TimeLocation: formatter defaults, local time
+//   - ServiceName: "" (executable name)
+//   - StaticFields: none
+//   - TimeKey/LevelKey/MessageKey: "" (timestamp, level, message)
+//   - LowercaseLevel: false
+//   - InlineFields/FieldsKey: false/"" (JSON fields appended to the message)
+//   - ProjectID: "" (GOOGLE_CLOUD_PROJECT)
+//   - MetricNamespace/MetricDimensions: none (no CloudWatch metrics; service dimension)
+//   - Columns: none (DefaultCSVColumns)
+//   - Journald: false
+//   - Limits: 64KB per field value, 1MB per entry, messages and field count unlimited
 func LoadConfigFromEnv() *LogConfig {
 	// Load .env and OS environment
 	ygggo_env.LoadEnv()
@@ -60,9 +155,15 @@ func LoadConfigFromEnv() *LogConfig {
 	consoleStr := ygggo_env.GetStr("YGGGO_LOG_CONSOLE", "false")
 	config.Console = parseBool(consoleStr)
 
-	// Color
-	colorStr := ygggo_env.GetStr("YGGGO_LOG_COLOR", "false")
-	config.Color = parseBool(colorStr)
+	// Color: true|false|auto, and the theme
+	config.ColorMode = parseColorMode(ygggo_env.GetStr("YGGGO_LOG_COLOR", "auto"))
+	config.Color = config.ColorMode == ColorAlways
+	config.Theme = config.envTheme("YGGGO_LOG_THEME")
+
+	// Caller hyperlinks: vscode, idea, or a template such as https://host/repo/blob/{revision}/{path}#L{line}
+	config.CallerLink = ygggo_env.GetStr("YGGGO_LOG_CALLER_LINK", "")
+	// Caller shown as a repository-relative path instead of the file name, e.g. for terminals without hyperlinks
+	config.CallerPath = parseBool(ygggo_env.GetStr("YGGGO_LOG_CALLER_PATH", "false"))
 
 	// File size
 	fileSizeStr := ygggo_env.GetStr("YGGGO_LOG_FILE_SIZE", "100M")
@@ -72,6 +173,63 @@ func LoadConfigFromEnv() *LogConfig {
 	fileNumStr := ygggo_env.GetStr("YGGGO_LOG_FILE_NUM", "3")
 	config.FileNum = parseFileNum(fileNumStr)
 
+	// Time-based rotation in YGGGO_LOG_TIMEZONE: hourly, daily, weekly or "0 */6 * * *"
+	config.Rotate = strings.TrimSpace(ygggo_env.GetStr("YGGGO_LOG_ROTATE", ""))
+
+	// Retention of rotated files: age such as 7d, 2w or 36h, and total size such as 2G
+	config.MaxAge = config.envAge("YGGGO_LOG_FILE_MAX_AGE")
+	config.TotalSize = config.envSize("YGGGO_LOG_TOTAL_SIZE", "")
+
+	// Buffered file output: buffer size such as 64KB, flush interval and flush level
+	config.FileBuffer = int(config.envSize("YGGGO_LOG_FILE_BUFFER", "0"))
+	config.FlushInterval = parseFlushInterval(ygggo_env.GetStr("YGGGO_LOG_FLUSH_INTERVAL", "1s"))
+	config.FlushLevel = parseLogLevel(ygggo_env.GetStr("YGGGO_LOG_FLUSH_LEVEL", "ERROR"))
+
+	// Compression of rotated files: true|false or a gzip level 1-9
+	config.Compress = config.envCompress("YGGGO_LOG_COMPRESS")
+
+	// Text layout pattern
+	config.Pattern = config.envPattern("YGGGO_LOG_PATTERN")
+	config.FilePattern = config.envPattern("YGGGO_LOG_FILE_PATTERN")
+
+	// Timestamps: layout (or rfc3339/iso8601/epoch...), precision (s/ms/us/ns), timezone
+	config.TimeLayout = parseTimeLayout(ygggo_env.GetStr("YGGGO_LOG_TIME_FORMAT", ""))
+	config.TimePrecision = parseTimePrecision(ygggo_env.GetStr("YGGGO_LOG_TIME_PRECISION", ""))
+	config.TimeLocation = config.envTimeLocation("YGGGO_LOG_TIMEZONE")
+
+	// Service name
+	config.ServiceName = strings.TrimSpace(ygggo_env.GetStr("YGGGO_LOG_SERVICE_NAME", ""))
+
+	// Static fields: service=api,env=prod
+	config.StaticFields = parseStaticFields(ygggo_env.GetStr("YGGGO_LOG_STATIC_FIELDS", ""))
+
+	// JSON layout: key names, lowercase levels and where fields go (inline or nested under a key)
+	config.TimeKey = strings.TrimSpace(ygggo_env.GetStr("YGGGO_LOG_TIME_KEY", ""))
+	config.LevelKey = strings.TrimSpace(ygggo_env.GetStr("YGGGO_LOG_LEVEL_KEY", ""))
+	config.MessageKey = strings.TrimSpace(ygggo_env.GetStr("YGGGO_LOG_MESSAGE_KEY", ""))
+	config.LowercaseLevel = parseBool(ygggo_env.GetStr("YGGGO_LOG_LOWERCASE_LEVEL", "false"))
+	config.InlineFields = parseBool(ygggo_env.GetStr("YGGGO_LOG_INLINE_FIELDS", "false"))
+	config.FieldsKey = strings.TrimSpace(ygggo_env.GetStr("YGGGO_LOG_FIELDS_KEY", ""))
+
+	// Cloud layouts: GCP project for trace links, CloudWatch metric namespace and dimensions
+	config.ProjectID = strings.TrimSpace(ygggo_env.GetStr("YGGGO_LOG_GCP_PROJECT", ""))
+	config.MetricNamespace = strings.TrimSpace(ygggo_env.GetStr("YGGGO_LOG_METRIC_NAMESPACE", ""))
+	config.MetricDimensions = parseNames(ygggo_env.GetStr("YGGGO_LOG_METRIC_DIMENSIONS", ""))
+
+	// CSV/TSV columns: time,level,message,user
+	config.Columns = parseNames(ygggo_env.GetStr("YGGGO_LOG_COLUMNS", ""))
+
+	// journald
+	config.Journald = parseBool(ygggo_env.GetStr("YGGGO_LOG_JOURNALD", "false"))
+
+	// Size limits: 0 disables a limit
+	config.Limits = Limits{
+		MaxMessageSize: int(config.envSize("YGGGO_LOG_MAX_MESSAGE_SIZE", "0")),
+		MaxFieldSize:   int(config.envSize("YGGGO_LOG_MAX_FIELD_SIZE", "64KB")),
+		MaxFields:      config.envCount("YGGGO_LOG_MAX_FIELDS"),
+		MaxEntrySize:   int(config.envSize("YGGGO_LOG_MAX_ENTRY_SIZE", "1MB")),
+	}
+
 	return config
 }
 
@@ -93,6 +251,31 @@ func parseLogLevel(levelStr string) LogLevel {
 	}
 }
 
+// parseNames 解析以逗号分隔的名称（如 CSV 列名、指标维度），忽略空名称
+func parseNames(namesStr string) []string {
+	var names []string
+	for _, name := range strings.Split(namesStr, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseStaticFields 解析 key=value 以逗号分隔的常量字段，忽略没有键名的项
+func parseStaticFields(fieldsStr string) []Field {
+	var fields []Field
+	for _, pair := range strings.Split(fieldsStr, ",") {
+		key, value, _ := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		fields = append(fields, String(key, strings.TrimSpace(value)))
+	}
+	return fields
+}
+
 // GetLogEnv 现在在 singleton.go 中实现为单例模式
 
 // NewLoggerFromEnvWithOutput creates a Logger using environment settings but
@@ -101,43 +284,285 @@ func NewLoggerFromEnvWithOutput(output io.Writer) *Logger {
 	config := LoadConfigFromEnv()
 	logger := NewLogger(output)
 	logger.minLevel = config.Level
+	logger.limits = config.Limits
 
-	if config.Color {
-		logger.formatter = NewColorFormatter()
+	if config.Format == PrettyFormat || config.colorEnabled(output) {
+		logger.SetFormatter(createConsoleFormatter(config, output))
+	} else if pf := createPatternFormatter(config, config.Pattern); pf != nil {
+		logger.SetFormatter(pf)
 	} else {
-		logger.formatter = createFormatter(config.Format)
+		logger.SetFormatter(createFormatter(config.Format, config.formatterOptions()...))
 	}
+	reportConfigErrors(logger, config.Errors)
 	return logger
 }
 
+// reportConfigErrors logs each ignored setting as a WARNING entry, regardless
+// of the logger's minimum level, so that a misconfiguration is never silent.
+func reportConfigErrors(l *Logger, errs []error) {
+	for _, err := range errs {
+		e := acquireEntry(WarningLevel, "ignored invalid logging configuration", 1)
+		e.Fields = append(e.Fields, Err(err))
+		l.write(e)
+	}
+}
+
+// colorEnabled reports whether console output written to w should be colored.
+func (c *LogConfig) colorEnabled(w io.Writer) bool {
+	mode := c.ColorMode
+	if c.Color {
+		mode = ColorAlways
+	}
+	return ColorEnabled(mode, w)
+}
+
+// createConsoleFormatter returns the console formatter for w: the text layout
+// pattern when one is configured for the text format (without colors),
+// PrettyFormatter for the pretty format and ColorFormatter otherwise, using the
+// configured theme and caller links when colors are enabled for w and no escape
+// sequences when they are not.
+func createConsoleFormatter(config *LogConfig, w io.Writer) EntryFormatter {
+	if pf := createPatternFormatter(config, config.Pattern); pf != nil {
+		return pf
+	}
+	opts := append(config.formatterOptions(), WithTheme(NoColorTheme))
+	if config.CallerPath {
+		opts = append(opts, WithCallerPath())
+	}
+	if config.colorEnabled(w) {
+		theme, _ := ThemeByName(config.Theme) // validated by LoadConfigFromEnv
+		opts = append(opts, WithTheme(theme), WithCallerLink(config.CallerLink))
+	}
+	if config.Format == PrettyFormat {
+		return NewPrettyFormatter(opts...)
+	}
+	return NewColorFormatter(opts...)
+}
+
+// createPatternFormatter returns a PatternFormatter for the console pattern
+// when the format is text. Invalid patterns are ignored so that a typo in the
+// environment never prevents logging.
+func createPatternFormatter(config *LogConfig, pattern string) *PatternFormatter {
+	if pattern == "" || config.Format != TextFormat {
+		return nil
+	}
+	pf, err := NewPatternFormatter(pattern, config.formatterOptions()...)
+	if err != nil {
+		return nil
+	}
+	return pf
+}
+
+// createFileFormatter picks the formatter for the log file under conventions:
+// JSON for the text, json and pretty formats (or the file text layout pattern
+// when one is configured), and the matching formatter for any other format.
+// The console pattern never applies to the file.
+func createFileFormatter(config *LogConfig) EntryFormatter {
+	switch config.Format {
+	case TextFormat, JsonFormat, PrettyFormat:
+		if config.FilePattern != "" {
+			if pf, err := NewPatternFormatter(config.FilePattern, config.formatterOptions()...); err == nil {
+				return pf
+			}
+		}
+		return NewJsonFormatter(config.formatterOptions()...)
+	default:
+		return createFormatter(config.Format, config.formatterOptions()...)
+	}
+}
+
 // NewLoggerFromConfig creates a Logger that follows convention-over-configuration
 // defaults: colored console + JSON file with rotation, INFO level, and async console
-// writes. File path defaults to logs/YYYYMMDD_HHMMSS.log when not provided.
+// writes. File path defaults to logs/YYYYMMDD_HHMMSS.log when not provided; that
+// file is created on the first write.
+// Console colors follow ColorEnabled for stdout and the configured theme, and
+// settings recorded in config.Errors are logged as warnings.
+// The pretty format switches the console to PrettyFormatter, and a text layout
+// Pattern replaces the console layout; the file keeps JSON unless FilePattern is
+// set. With Journald set,
+// console output goes to systemd-journald instead when its socket is available.
 func NewLoggerFromConfig(config *LogConfig) *Logger {
-	// Console: colored + async buffering
-	console := NewAsyncWriter(os.Stdout, 1024)
+	// Console: colored (when stdout is a terminal) + async buffering, or journald
+	var console io.Writer
+	var consoleFormatter EntryFormatter
+	if config.Journald {
+		if jw, err := NewJournaldWriter(""); err == nil {
+			console, consoleFormatter = jw, NewJournaldFormatter(config.formatterOptions()...)
+		}
+	}
+	if console == nil {
+		console = NewAsyncWriter(os.Stdout, 1024)
+		consoleFormatter = createConsoleFormatter(config, os.Stdout)
+	}
 
-	// File: rotation (size/count), default path under logs/
-	filePath := config.OutputFile
-	if filePath == "" {
-		_ = os.MkdirAll("logs", 0755)
-		filePath = time.Now().Format("logs/20060102_150405.log")
+	// File: rotation (size/time/count), default path under logs/
+	var rot *RotatingWriter
+	if config.OutputFile == "" {
+		// The default file and directory are created on the first write, so that
+		// importing the package (or running its tests) leaves no empty log files
+		rot = newDeferredRotatingWriter(time.Now().Format("logs/20060102_150405.log"), config.FileSize, config.FileNum)
+	} else {
+		rot, _ = NewRotatingWriter(config.OutputFile, config.FileSize, config.FileNum)
 	}
-	rot, _ := NewRotatingWriter(filePath, config.FileSize, config.FileNum)
 	var fileOut io.Writer
+	errs := slices.Clip(config.Errors)
 	if rot != nil {
-		fileOut = rot // sync by default; stable for tests & Windows
+		if config.Rotate != "" {
+			// An invalid schedule is reported and ignored so that a typo never prevents logging
+			if schedule, err := ParseSchedule(config.Rotate, config.TimeLocation); err == nil {
+				rot.SetSchedule(schedule)
+			} else {
+				errs = append(errs, fmt.Errorf("invalid YGGGO_LOG_ROTATE %q: %w; time-based rotation disabled", config.Rotate, err))
+			}
+		}
+		rot.SetRetention(Retention{MaxAge: config.MaxAge, MaxTotalSize: config.TotalSize})
+		if err := rot.SetCompression(config.Compress); err != nil {
+			errs = append(errs, fmt.Errorf("invalid compression: %w; compression disabled", err))
+		}
+		fileOut = rot // synchronous writes to a file kept open
+		if config.FileBuffer > 0 {
+			bw := NewBufferedWriter(rot, config.FileBuffer)
+			bw.SetFlushInterval(config.FlushInterval)
+			bw.SetFlushLevel(config.FlushLevel)
+			fileOut = bw
+		}
 	}
 
-	// Combined formatter: console (color) + file (JSON)
-	combined := NewCombinedFormatter(console, fileOut)
+	// Combined formatter: console (color, pattern or journald) + file (JSON or file pattern)
+	combined := NewCombinedFormatterWith(console, consoleFormatter, fileOut, createFileFormatter(config))
 
 	logger := NewLogger(io.Discard) // formatter writes to destinations
 	logger.minLevel = config.Level
-	logger.formatter = combined
+	logger.limits = config.Limits
+	logger.SetFormatter(combined)
+	reportConfigErrors(logger, errs)
 	return logger
 }
 
+// envPattern reads a text layout pattern from the environment variable name.
+// An invalid pattern is dropped and recorded in Errors.
+func (c *LogConfig) envPattern(name string) string {
+	pattern := ygggo_env.GetStr(name, "")
+	if pattern == "" {
+		return ""
+	}
+	if _, err := NewPatternFormatter(pattern); err != nil {
+		c.Errors = append(c.Errors, fmt.Errorf("invalid %s %q: %w; built-in layout used", name, pattern, err))
+		return ""
+	}
+	return pattern
+}
+
+// envTheme reads a console color theme name from the environment variable
+// name. An unknown theme is dropped and recorded in Errors.
+func (c *LogConfig) envTheme(name string) string {
+	theme := strings.TrimSpace(ygggo_env.GetStr(name, ""))
+	if _, ok := ThemeByName(theme); !ok {
+		c.Errors = append(c.Errors, fmt.Errorf("invalid %s %q: expected default, vivid, solarized, mono or none; default theme used", name, theme))
+		return ""
+	}
+	return theme
+}
+
+// envTimeLocation reads a timezone such as UTC, Local or Asia/Shanghai from
+// the environment variable name. An unknown zone keeps local time (nil) and is
+// recorded in Errors.
+func (c *LogConfig) envTimeLocation(name string) *time.Location {
+	value := strings.TrimSpace(ygggo_env.GetStr(name, ""))
+	loc, err := parseTimeLocation(value)
+	if err != nil {
+		c.Errors = append(c.Errors, fmt.Errorf("invalid %s %q: %w; local time used", name, value, err))
+		return nil
+	}
+	return loc
+}
+
+// envCompress reads the rotated file compression setting, true|false or a gzip
+// level 1-9, from the environment variable name. An invalid value disables
+// compression and is recorded in Errors.
+func (c *LogConfig) envCompress(name string) int {
+	value := ygggo_env.GetStr(name, "false")
+	level, ok := parseCompress(value)
+	if !ok {
+		c.Errors = append(c.Errors, fmt.Errorf("invalid %s %q: expected true, false or a gzip level 1-9; compression disabled", name, value))
+	}
+	return level
+}
+
+// envAge reads a retention age such as 7d, 2w or 36h from the environment
+// variable name. An invalid value disables the setting (0) and is recorded in Errors.
+func (c *LogConfig) envAge(name string) time.Duration {
+	value := strings.TrimSpace(ygggo_env.GetStr(name, ""))
+	if value == "" {
+		return 0
+	}
+	age, ok := parseAge(value)
+	if !ok {
+		c.Errors = append(c.Errors, fmt.Errorf("invalid %s %q: expected an age such as 7d, 2w or 36h; setting disabled", name, value))
+		return 0
+	}
+	return age
+}
+
+// envCount reads a non-negative count such as 20 from the environment variable
+// name. An invalid value disables the setting (0) and is recorded in Errors.
+func (c *LogConfig) envCount(name string) int {
+	value := strings.TrimSpace(ygggo_env.GetStr(name, ""))
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		c.Errors = append(c.Errors, fmt.Errorf("invalid %s %q: expected a non-negative count; setting disabled", name, value))
+		return 0
+	}
+	return n
+}
+
+// envSize reads a byte size such as 2G or 64KB from the environment variable
+// name. An invalid value disables the setting (0) and is recorded in Errors,
+// so that a typo never turns into an unintended size.
+func (c *LogConfig) envSize(name, defaultValue string) int64 {
+	value := strings.TrimSpace(ygggo_env.GetStr(name, defaultValue))
+	if value == "" {
+		return 0
+	}
+	size, ok := parseSize(value)
+	if !ok {
+		c.Errors = append(c.Errors, fmt.Errorf("invalid %s %q: expected a size such as 512KB or 2G; setting disabled", name, value))
+		return 0
+	}
+	return size
+}
+
+// parseCompress parses the rotated file compression setting: a boolean enables
+// the default gzip level, and a number from 1 to 9 selects the level. Any other
+// value disables compression and reports ok == false.
+func parseCompress(compressStr string) (level int, ok bool) {
+	compressStr = strings.ToLower(strings.TrimSpace(compressStr))
+	if level, err := strconv.Atoi(compressStr); err == nil && level >= gzip.BestSpeed && level <= gzip.BestCompression {
+		return level, true
+	}
+	switch compressStr {
+	case "true", "yes", "on":
+		return gzip.DefaultCompression, true
+	case "", "false", "0", "no", "off":
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// parseFlushInterval parses a flush interval such as 500ms or 2s; invalid or
+// negative values fall back to DefaultFlushInterval and 0 disables timed flushes.
+func parseFlushInterval(intervalStr string) time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(intervalStr))
+	if err != nil || d < 0 {
+		return DefaultFlushInterval
+	}
+	return d
+}
+
 // parseBool parses a boolean-ish string into a bool.
 func parseBool(boolStr string) bool {
 	switch strings.ToLower(boolStr) {
@@ -3,6 +3,7 @@ package ygggo_log
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -160,3 +161,25 @@ func TestConfigStruct(t *testing.T) {
 		t.Errorf("Expected output file to be test.log, got: %s", config.OutputFile)
 	}
 }
+
+func TestNewLoggerFromConfig_DefaultFileCreatedOnFirstWrite(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	config := LoadConfigFromEnv()
+	config.OutputFile = ""
+	logger := NewLoggerFromConfig(config)
+	defer logger.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "logs")); !os.IsNotExist(err) {
+		t.Fatalf("Expected no logs directory before the first write, got: %v", err)
+	}
+	logger.Info("first write")
+	files, _ := filepath.Glob(filepath.Join(dir, "logs", "*.log"))
+	if len(files) != 1 {
+		t.Fatalf("Expected the default log file after the first write, got: %v", files)
+	}
+	if data, _ := os.ReadFile(files[0]); !strings.Contains(string(data), "first write") {
+		t.Errorf("Unexpected default file content: %q", data)
+	}
+}
@@ -0,0 +1,166 @@
+package ygggo_log
+
+import (
+	"unicode/utf8"
+)
+
+// WithoutEscaping 关闭文本、彩色、布局和开发环境格式化器对消息和参数的转义，原样输出。
+// 默认情况下这些格式化器会转义换行、ANSI 转义序列等控制字符，避免用户输入伪造日志行或改变终端颜色，
+// 并为包含空格、等号或引号的参数值加上双引号
+func WithoutEscaping() FormatterOption {
+	return func(o *formatterOptions) {
+		o.rawText = true
+	}
+}
+
+// appendTextEscaped 追加 s，将控制字符写成转义形式：\n、\r 写作 \n、\r，ESC 等其他 C0 控制字符
+// 和 DEL 写作 \x1b 形式，C1 控制字符、行/段分隔符和双向文本控制字符写作 \u009b 形式，
+// 非法 UTF-8 字节写作 \xff 形式；制表符和其他字符原样保留
+func appendTextEscaped(dst []byte, s string) []byte {
+	return appendEscaped(dst, s, false)
+}
+
+// appendTextQuoted 追加带双引号的 s，在 appendTextEscaped 的基础上转义双引号、反斜杠和制表符
+func appendTextQuoted(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	dst = appendEscaped(dst, s, true)
+	return append(dst, '"')
+}
+
+// appendEscaped 转义控制字符后追加 s；quoted 为 true 时还转义双引号、反斜杠和制表符
+func appendEscaped[T string | []byte](dst []byte, s T, quoted bool) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != 0x7f && (!quoted || c != '"' && c != '\\') || c == '\t' && !quoted {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			default:
+				dst = append(dst, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(string(s[i:min(i+utf8.UTFMax, len(s))]))
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+			i++
+			start = i
+			continue
+		}
+		if isUnsafeRune(r) {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', hexDigits[r>>12&0xf], hexDigits[r>>8&0xf], hexDigits[r>>4&0xf], hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	return append(dst, s[start:]...)
+}
+
+// isUnsafeRune 判断是否为需要转义的非 ASCII 控制字符：C1 控制字符（其中 U+009B 可作为 CSI）、
+// 行/段分隔符以及可以重排显示顺序的双向文本控制字符
+func isUnsafeRune(r rune) bool {
+	return r >= 0x80 && r <= 0x9f ||
+		r == 0x2028 || r == 0x2029 ||
+		r >= 0x202a && r <= 0x202e ||
+		r >= 0x2066 && r <= 0x2069
+}
+
+// needsTextQuote 判断参数值是否需要加双引号：为空，或包含空格、等号、双引号或需要转义的字符
+func needsTextQuote[T string | []byte](s T) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(string(s[i:min(i+utf8.UTFMax, len(s))]))
+		if r == utf8.RuneError && size == 1 || isUnsafeRune(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// appendTextKey 追加参数键名，需要时加双引号
+func appendTextKey(dst []byte, key string) []byte {
+	if needsTextQuote(key) {
+		return appendTextQuoted(dst, key)
+	}
+	return append(dst, key...)
+}
+
+// appendTextValue 追加参数值：包含空格、等号、双引号或控制字符的值加双引号并转义，数值原样输出
+func appendTextValue(dst []byte, f Field) []byte {
+	switch f.Type {
+	case IntType, UintType, FloatType, BoolType:
+		return appendFieldValue(dst, f)
+	case StringType:
+		if needsTextQuote(f.str) {
+			return appendTextQuoted(dst, f.str)
+		}
+		return append(dst, f.str...)
+	}
+	start := len(dst)
+	dst = appendFieldValue(dst, f)
+	if !needsTextQuote(dst[start:]) {
+		return dst
+	}
+	s := string(dst[start:])
+	return appendTextQuoted(dst[:start], s)
+}
+
+// appendEscapedTextFields 与 appendTextFields 相同，但带键名的参数按 appendTextKey 和
+// appendTextValue 输出，位置参数只转义控制字符
+func appendEscapedTextFields(dst []byte, fields []Field) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		if f.Key == "" {
+			dst = appendFieldValueFunc(dst, f, appendTextEscaped)
+			continue
+		}
+		dst = appendTextKey(dst, f.Key)
+		dst = append(dst, '=')
+		dst = appendTextValue(dst, f)
+	}
+	return dst
+}
+
+// appendSafeMessageText 追加消息正文和参数；raw 为 false 时转义消息并按 appendEscapedTextFields 输出参数
+func appendSafeMessageText(dst []byte, e *Entry, raw bool) []byte {
+	if raw {
+		return appendMessageText(dst, e, appendRaw)
+	}
+	dst = appendTextEscaped(dst, e.Message)
+	if len(e.Fields) > 0 {
+		dst = append(dst, ' ')
+		dst = appendEscapedTextFields(dst, e.Fields)
+	}
+	return dst
+}
@@ -0,0 +1,128 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// formatWith 用指定格式化器记录一条日志并返回输出
+func formatWith(f Formatter, log func(l *Logger)) string {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(f)
+	log(logger)
+	return buf.String()
+}
+
+func TestTextFormatter_EscapesForgedLines(t *testing.T) {
+	out := formatWith(NewTextFormatter(), func(l *Logger) {
+		l.Info("login\n2024-01-01 00:00:00 [ERROR] forged", String("user", "tom\r\n[INFO] admin"))
+	})
+
+	if strings.Count(out, "\n") != 1 {
+		t.Fatalf("Expected a single line, got: %q", out)
+	}
+	want := `login\n2024-01-01 00:00:00 [ERROR] forged user="tom\r\n[INFO] admin"`
+	if !strings.Contains(out, want) {
+		t.Errorf("Expected %q in output, got: %q", want, out)
+	}
+}
+
+func TestTextFormatter_EscapesANSI(t *testing.T) {
+	out := formatWith(NewTextFormatter(), func(l *Logger) {
+		l.Info("\033[31mred", String("v", "\033[2J"), "\u009b31m")
+	})
+
+	if strings.ContainsAny(out, "\033\u009b") {
+		t.Fatalf("Expected no escape characters in output, got: %q", out)
+	}
+	if !strings.Contains(out, `\x1b[31mred v="\x1b[2J" \u009b31m`) {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
+
+func TestTextFormatter_QuotesValues(t *testing.T) {
+	out := formatWith(NewTextFormatter(), func(l *Logger) {
+		l.Info("msg",
+			String("plain", "tom"),
+			String("space", "tom smith"),
+			String("eq", "a=b"),
+			String("quote", `say "hi"`),
+			String("empty", ""),
+			String("tab", "a\tb"),
+			String("my key", "x"),
+			Int("n", -5),
+			"positional value",
+		)
+	})
+
+	want := `msg plain=tom space="tom smith" eq="a=b" quote="say \"hi\"" empty="" tab="a\tb" "my key"=x n=-5 positional value`
+	if !strings.Contains(out, want) {
+		t.Errorf("Expected %q in output, got: %q", want, out)
+	}
+}
+
+func TestTextFormatter_EscapesUnicodeControls(t *testing.T) {
+	out := formatWith(NewTextFormatter(), func(l *Logger) {
+		l.Info("a\u2028b\u202ec", String("name", "中文"), String("bad", "\xff"))
+	})
+
+	if !strings.Contains(out, `a\u2028b\u202ec name=中文 bad="\xff"`) {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
+
+func TestTextFormatter_WithoutEscaping(t *testing.T) {
+	out := formatWith(NewTextFormatter(WithoutEscaping()), func(l *Logger) {
+		l.Info("line1\nline2", String("v", "a b"))
+	})
+
+	if !strings.Contains(out, "line1\nline2 v=a b\n") {
+		t.Errorf("Expected raw output, got: %q", out)
+	}
+}
+
+func TestPatternFormatter_Escapes(t *testing.T) {
+	f, err := NewPatternFormatter("%level %msg %fields")
+	if err != nil {
+		t.Fatalf("NewPatternFormatter failed: %v", err)
+	}
+	out := formatWith(f, func(l *Logger) { l.Info("a\nb", String("v", "x\ny")) })
+
+	if out != "INFO a\\nb v=\"x\\ny\"\n" {
+		t.Errorf("Unexpected output: %q", out)
+	}
+}
+
+func TestColorFormatter_Escapes(t *testing.T) {
+	out := formatWith(NewColorFormatter(), func(l *Logger) {
+		l.Info("a\nb", String("v", "\033[31m x"))
+	})
+
+	if strings.Count(out, "\n") != 1 {
+		t.Fatalf("Expected a single line, got: %q", out)
+	}
+	plain := ansiPattern.ReplaceAllString(out, "")
+	if !strings.Contains(plain, `a\nb v="\x1b[31m x"`) {
+		t.Errorf("Unexpected output: %q", plain)
+	}
+}
+
+func TestPrettyFormatter_Escapes(t *testing.T) {
+	lines := prettyLines(t, func(l *Logger) { l.Info("a\033[2Jb", String("v", "x\ry")) })
+
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], `a\x1b[2Jb  v=x\ry`) {
+		t.Errorf("Unexpected output: %q", lines)
+	}
+}
+
+func TestAppendTextValue_NonString(t *testing.T) {
+	if got := string(appendTextValue(nil, Any("m", map[string]int{"a": 1}))); got != "map[a:1]" {
+		t.Errorf("Unexpected value: %q", got)
+	}
+	if got := string(appendTextValue(nil, Any("s", []string{"a", "b"}))); got != `"[a b]"` {
+		t.Errorf("Expected quoted value, got: %q", got)
+	}
+}
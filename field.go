@@ -0,0 +1,219 @@
+package ygggo_log
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// FieldType 标识 Field 中保存的值类型，格式化器据此选择无反射的编码方式
+type FieldType uint8
+
+const (
+	AnyType      FieldType = iota // 任意值，编码时回退到反射
+	StringType                    // string
+	IntType                       // 有符号整数
+	UintType                      // 无符号整数
+	FloatType                     // 浮点数
+	BoolType                      // 布尔值
+	DurationType                  // time.Duration
+	TimeType                      // time.Time
+	ErrorType                     // error
+)
+
+// Field 是一个带类型的 key=value 日志参数。
+// 通过 String、Int、Bool 等构造函数创建的 Field 不会把值装箱成 interface，
+// 配合 Logger.Log 使用时整条日志的编码过程不产生堆分配。
+// Key 为空表示位置参数，文本格式中只输出值本身。
+type Field struct {
+	Key  string
+	Type FieldType
+	nsec int32 // 时间的纳秒部分
+
+	num   int64  // 整数、布尔、浮点（位模式）、时长、时间的 Unix 秒
+	str   string // 字符串值
+	iface any    // error、时区或任意值
+}
+
+// String 创建字符串类型的 Field
+func String(key, value string) Field {
+	return Field{Key: key, Type: StringType, str: value}
+}
+
+// Int 创建 int 类型的 Field
+func Int(key string, value int) Field {
+	return Int64(key, int64(value))
+}
+
+// Int64 创建 int64 类型的 Field
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: IntType, num: value}
+}
+
+// Uint64 创建 uint64 类型的 Field
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Type: UintType, num: int64(value)}
+}
+
+// Float64 创建 float64 类型的 Field
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: FloatType, num: int64(math.Float64bits(value))}
+}
+
+// Bool 创建布尔类型的 Field
+func Bool(key string, value bool) Field {
+	var n int64
+	if value {
+		n = 1
+	}
+	return Field{Key: key, Type: BoolType, num: n}
+}
+
+// Duration 创建 time.Duration 类型的 Field
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, num: int64(value)}
+}
+
+// Time 创建 time.Time 类型的 Field，输出时使用 RFC3339Nano。
+// 秒和纳秒分开保存，零值时间和远期时间等任意年份都能正确输出
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, num: value.Unix(), nsec: int32(value.Nanosecond()), iface: value.Location()}
+}
+
+// Err 创建键为 "error" 的错误 Field；err 为 nil 时值为 <nil>
+func Err(err error) Field {
+	return Field{Key: "error", Type: ErrorType, iface: err}
+}
+
+// 链路追踪参数的键名，云平台格式会把它们映射到各自的追踪字段
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// TraceID 创建链路追踪 ID 参数（通常为 32 位十六进制的 W3C trace id）
+func TraceID(id string) Field {
+	return String(TraceIDKey, id)
+}
+
+// SpanID 创建 span ID 参数（通常为 16 位十六进制）
+func SpanID(id string) Field {
+	return String(SpanIDKey, id)
+}
+
+// Any 根据值的动态类型选择合适的 Field 构造函数，未知类型保存为 AnyType
+func Any(key string, value any) Field {
+	switch v := value.(type) {
+	case Field:
+		return v
+	case string:
+		return String(key, v)
+	case bool:
+		return Bool(key, v)
+	case int:
+		return Int64(key, int64(v))
+	case int8:
+		return Int64(key, int64(v))
+	case int16:
+		return Int64(key, int64(v))
+	case int32:
+		return Int64(key, int64(v))
+	case int64:
+		return Int64(key, v)
+	case uint:
+		return Uint64(key, uint64(v))
+	case uint8:
+		return Uint64(key, uint64(v))
+	case uint16:
+		return Uint64(key, uint64(v))
+	case uint32:
+		return Uint64(key, uint64(v))
+	case uint64:
+		return Uint64(key, v)
+	case float32:
+		return Float64(key, float64(v))
+	case float64:
+		return Float64(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return Field{Key: key, Type: ErrorType, iface: v}
+	default:
+		return Field{Key: key, Type: AnyType, iface: v}
+	}
+}
+
+// Value 返回 Field 中保存的原始值
+func (f Field) Value() any {
+	switch f.Type {
+	case StringType:
+		return f.str
+	case IntType:
+		return f.num
+	case UintType:
+		return uint64(f.num)
+	case FloatType:
+		return f.float()
+	case BoolType:
+		return f.num == 1
+	case DurationType:
+		return time.Duration(f.num)
+	case TimeType:
+		return f.time()
+	default:
+		return f.iface
+	}
+}
+
+// String 以 %v 的形式返回值的文本表示
+func (f Field) String() string {
+	if f.Type == StringType {
+		return f.str
+	}
+	return string(appendFieldValue(nil, f))
+}
+
+func (f Field) float() float64 {
+	return math.Float64frombits(uint64(f.num))
+}
+
+func (f Field) time() time.Time {
+	t := time.Unix(f.num, int64(f.nsec))
+	if loc, ok := f.iface.(*time.Location); ok && loc != nil {
+		t = t.In(loc)
+	}
+	return t
+}
+
+// argsToFields 将 Logger 的可变参数转换为 Field，支持的形式与 Info 等方法的说明一致：
+//   - Field 原样追加
+//   - map[string]any 每个键值对转换为一个 Field
+//   - "key=value" 字符串拆分为字符串 Field
+//   - 其余字符串和值作为位置参数（Key 为空）
+func argsToFields(dst []Field, args []any) []Field {
+	for _, a := range args {
+		switch v := a.(type) {
+		case nil:
+			continue
+		case Field:
+			dst = append(dst, v)
+		case []Field:
+			dst = append(dst, v...)
+		case map[string]any:
+			for k, val := range v {
+				dst = append(dst, Any(k, val))
+			}
+		case string:
+			if i := strings.IndexByte(v, '='); i > 0 {
+				dst = append(dst, String(v[:i], v[i+1:]))
+			} else {
+				dst = append(dst, String("", v))
+			}
+		default:
+			dst = append(dst, Any("", v))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,135 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAny_TypedFields(t *testing.T) {
+	testCases := []struct {
+		value    any
+		typ      FieldType
+		expected string
+	}{
+		{"hello", StringType, "hello"},
+		{42, IntType, "42"},
+		{int8(-3), IntType, "-3"},
+		{uint32(7), UintType, "7"},
+		{1.5, FloatType, "1.5"},
+		{float32(0.25), FloatType, "0.25"},
+		{true, BoolType, "true"},
+		{1500 * time.Millisecond, DurationType, "1.5s"},
+		{errors.New("boom"), ErrorType, "boom"},
+		{[]int{1, 2}, AnyType, "[1 2]"},
+	}
+
+	for _, tc := range testCases {
+		f := Any("k", tc.value)
+		if f.Type != tc.typ {
+			t.Errorf("Expected type %v for %#v, got: %v", tc.typ, tc.value, f.Type)
+		}
+		if got := f.String(); got != tc.expected {
+			t.Errorf("Expected %q for %#v, got: %q", tc.expected, tc.value, got)
+		}
+	}
+}
+
+func TestField_TimeKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 6000, loc)
+
+	f := Time("at", ts)
+
+	if got := f.String(); got != "2025-01-02T03:04:05.000006+08:00" {
+		t.Errorf("Unexpected time field text: %s", got)
+	}
+	if v, ok := f.Value().(time.Time); !ok || !v.Equal(ts) {
+		t.Errorf("Expected Value to return %v, got: %v", ts, f.Value())
+	}
+}
+
+func TestField_TimeOutsideUnixNanoRange(t *testing.T) {
+	testCases := map[string]time.Time{
+		"0001-01-01T00:00:00Z":           {},
+		"1600-02-29T12:00:00.5Z":         time.Date(1600, 2, 29, 12, 0, 0, 500000000, time.UTC),
+		"9999-12-31T23:59:59.999999999Z": time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC),
+	}
+	for want, ts := range testCases {
+		f := Time("at", ts)
+		if got := f.String(); got != want {
+			t.Errorf("Expected %s, got: %s", want, got)
+		}
+		if v, ok := f.Value().(time.Time); !ok || !v.Equal(ts) {
+			t.Errorf("Expected Value to return %v, got: %v", ts, f.Value())
+		}
+	}
+
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.Log(InfoLevel, "sentinel", Time("expires", time.Time{}))
+	if !strings.Contains(buf.String(), "expires=0001-01-01T00:00:00Z") {
+		t.Errorf("Expected the zero time in the output, got: %s", buf.String())
+	}
+}
+
+func TestArgsToFields(t *testing.T) {
+	fields := argsToFields(nil, []any{"d=xxx", "plain", nil, 42, Int("n", 1), map[string]any{"ok": true}})
+
+	if len(fields) != 5 {
+		t.Fatalf("Expected 5 fields, got: %d", len(fields))
+	}
+	if fields[0].Key != "d" || fields[0].String() != "xxx" {
+		t.Errorf("Expected d=xxx to split into key and value, got: %+v", fields[0])
+	}
+	if fields[1].Key != "" || fields[1].String() != "plain" {
+		t.Errorf("Expected positional string, got: %+v", fields[1])
+	}
+	if fields[2].Key != "" || fields[2].Type != IntType {
+		t.Errorf("Expected positional int, got: %+v", fields[2])
+	}
+	if fields[3].Key != "n" {
+		t.Errorf("Expected Field to be kept as is, got: %+v", fields[3])
+	}
+	if fields[4].Key != "ok" || fields[4].Type != BoolType {
+		t.Errorf("Expected map entry to become a bool field, got: %+v", fields[4])
+	}
+}
+
+func TestLogger_LogTypedFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+
+	logger.Log(WarningLevel, "typed", String("user", "tom"), Int("age", 18), Bool("vip", false))
+
+	out := buf.String()
+	if !strings.Contains(out, "[WARNING] typed user=tom age=18 vip=false\n") {
+		t.Errorf("Unexpected output: %s", out)
+	}
+}
+
+func TestLogger_LogBelowMinLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.minLevel = ErrorLevel
+
+	logger.Log(InfoLevel, "dropped", String("k", "v"))
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output below min level, got: %s", buf.String())
+	}
+}
+
+func TestEntry_CallerPointsToCallSite(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewColorFormatter())
+
+	logger.Info("where am i")
+
+	if !strings.Contains(buf.String(), "field_test.go:") {
+		t.Errorf("Expected caller to be the test file, got: %s", buf.String())
+	}
+}
@@ -1,9 +1,9 @@
 package ygggo_log
 
 import (
-	"encoding/json"
-	"fmt"
+	"cmp"
 	"io"
+	"slices"
 	"time"
 )
 
@@ -11,8 +11,20 @@ import (
 type LogFormat int
 
 const (
-	TextFormat LogFormat = iota // 文本格式（默认）
-	JsonFormat                  // JSON格式
+	TextFormat       LogFormat = iota // 文本格式（默认）
+	JsonFormat                        // JSON格式
+	LogfmtFormat                      // logfmt格式
+	ECSFormat                         // Elastic Common Schema JSON格式
+	GCPFormat                         // Google Cloud Logging JSON格式
+	DatadogFormat                     // Datadog JSON格式
+	CloudWatchFormat                  // AWS CloudWatch JSON格式
+	GELFFormat                        // Graylog GELF 1.1 格式
+	SyslogFormat                      // RFC 5424 syslog格式
+	OTLPFormat                        // OpenTelemetry OTLP/JSON格式
+	BinaryFormat                      // 紧凑二进制格式（使用 binlog 包解码）
+	CSVFormat                         // CSV格式
+	TSVFormat                         // TSV格式
+	PrettyFormat                      // 开发环境控制台格式
 )
 
 // String 返回日志格式的字符串表示
@@ -22,37 +34,198 @@ func (f LogFormat) String() string {
 		return "text"
 	case JsonFormat:
 		return "json"
+	case LogfmtFormat:
+		return "logfmt"
+	case ECSFormat:
+		return "ecs"
+	case GCPFormat:
+		return "gcp"
+	case DatadogFormat:
+		return "datadog"
+	case CloudWatchFormat:
+		return "cloudwatch"
+	case GELFFormat:
+		return "gelf"
+	case SyslogFormat:
+		return "syslog"
+	case OTLPFormat:
+		return "otlp"
+	case BinaryFormat:
+		return "binary"
+	case CSVFormat:
+		return "csv"
+	case TSVFormat:
+		return "tsv"
+	case PrettyFormat:
+		return "pretty"
 	default:
 		return "text"
 	}
 }
 
 // Formatter 日志格式化器接口
+// 实现应将整条日志（包括结尾换行）组装到同一个缓冲区后只调用一次 writer.Write，
+// 这样多个 goroutine 共享同一个 io.Writer 时日志行不会相互穿插。
 type Formatter interface {
 	Format(writer io.Writer, level LogLevel, message string)
 }
 
+// EntryFormatter 是可选的格式化器接口，直接接收结构化的 Entry。
+// Logger 优先使用该接口，参数以带类型的 Field 传入而不是预先拼接成字符串；
+// 内置格式化器都实现了它，并通过对象池复用缓冲区，避免每条日志的内存分配。
+type EntryFormatter interface {
+	Formatter
+	FormatEntry(writer io.Writer, entry *Entry)
+}
+
+// formatLegacy 通过 Formatter.Format 把 Entry 交给自定义的格式化器，参数以纯文本拼接到消息之后
+func formatLegacy(f Formatter, writer io.Writer, e *Entry) {
+	if len(e.Fields) == 0 {
+		f.Format(writer, e.Level, e.Message)
+		return
+	}
+	buf := getBuffer()
+	buf.b = appendMessageText(buf.b, e, appendRaw)
+	f.Format(writer, e.Level, string(buf.b))
+	putBuffer(buf)
+}
+
+// formatMessage 为直接调用 Format 的场景构造 Entry，调用位置记为 Format 的调用方
+func formatMessage(f EntryFormatter, writer io.Writer, level LogLevel, message string) {
+	e := acquireEntry(level, message, 2)
+	f.FormatEntry(writer, e)
+	releaseEntry(e)
+}
+
 // TextFormatter 文本格式化器
-type TextFormatter struct{}
+type TextFormatter struct {
+	time timeEncoder
+	raw  bool // 不转义消息和参数
+}
 
-// NewTextFormatter 创建文本格式化器
-func NewTextFormatter() *TextFormatter {
-	return &TextFormatter{}
+// NewTextFormatter 创建文本格式化器，默认时间布局为 2006-01-02 15:04:05；
+// 消息和参数中的控制字符默认转义，见 WithoutEscaping
+func NewTextFormatter(opts ...FormatterOption) *TextFormatter {
+	o := newFormatterOptions(opts)
+	return &TextFormatter{time: newTimeEncoder(o, "2006-01-02 15:04:05"), raw: o.rawText}
 }
 
 // Format 格式化为文本格式
 func (f *TextFormatter) Format(writer io.Writer, level LogLevel, message string) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	logEntry := fmt.Sprintf("%s [%s] %s\n", timestamp, level.String(), message)
-	writer.Write([]byte(logEntry))
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为文本格式：时间 [级别] 消息 参数
+func (f *TextFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	buf.b = f.time.append(buf.b, e.Time)
+	buf.b = append(buf.b, " ["...)
+	buf.b = append(buf.b, e.Level.String()...)
+	buf.b = append(buf.b, "] "...)
+	buf.b = appendSafeMessageText(buf.b, e, f.raw)
+	buf.b = append(buf.b, '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// jsonFieldsMode JsonFormatter 输出日志参数的方式
+type jsonFieldsMode uint8
+
+const (
+	jsonFieldsInMessage jsonFieldsMode = iota // 以文本形式附加在 message 中（默认）
+	jsonFieldsInline                          // 作为顶层字段输出
+	jsonFieldsNested                          // 嵌套在指定键下的对象中
+)
+
+// WithTimeKey 设置 JSON 中时间字段的键名，默认 timestamp
+func WithTimeKey(key string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.timeKey = key
+	}
+}
+
+// WithLevelKey 设置 JSON 中级别字段的键名，默认 level
+func WithLevelKey(key string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.levelKey = key
+	}
+}
+
+// WithMessageKey 设置 JSON 中消息字段的键名，默认 message
+func WithMessageKey(key string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.messageKey = key
+	}
+}
+
+// WithLowercaseLevel 以小写输出 JSON 中的级别名称，如 info、warning
+func WithLowercaseLevel() FormatterOption {
+	return func(o *formatterOptions) {
+		o.lowercaseLevel = true
+	}
+}
+
+// WithInlineFields 将日志参数作为 JSON 顶层字段输出，位置参数依次命名为 arg0、arg1……；
+// 与时间、级别、消息键同名的参数加 labels. 前缀，不会覆盖内置字段
+func WithInlineFields() FormatterOption {
+	return func(o *formatterOptions) {
+		o.fieldsMode = jsonFieldsInline
+	}
+}
+
+// WithFieldsKey 将日志参数嵌套输出到指定键下的对象中，如 {"fields":{"user":"tom"}}；
+// 没有参数时不输出该键。传入空字符串恢复默认行为（参数以文本形式附加在 message 中）
+func WithFieldsKey(key string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.fieldsKey = key
+		o.fieldsMode = jsonFieldsNested
+		if key == "" {
+			o.fieldsMode = jsonFieldsInMessage
+		}
+	}
 }
 
 // JsonFormatter JSON格式化器
-type JsonFormatter struct{}
+type JsonFormatter struct {
+	time           timeEncoder
+	timePrefix     string // {"timestamp":
+	levelPrefix    string // ,"level":"
+	messagePrefix  string // ,"message":
+	fieldsPrefix   string // ,"fields":{
+	lowercaseLevel bool
+	fieldsMode     jsonFieldsMode
+	keys           []string // 时间、级别和消息的键名，内联参数同名时加 labels. 前缀
+	static         []byte   // 预先编码的常量字段
+}
 
-// NewJsonFormatter 创建JSON格式化器
-func NewJsonFormatter() *JsonFormatter {
-	return &JsonFormatter{}
+// NewJsonFormatter 创建JSON格式化器，默认时间布局为 RFC3339；
+// 使用 EpochLayout 时 timestamp 输出为数字。键名、级别大小写、参数的输出方式
+// 和常量字段可通过 WithTimeKey、WithLowercaseLevel、WithFieldsKey、WithStaticFields 等配置
+func NewJsonFormatter(opts ...FormatterOption) *JsonFormatter {
+	o := newFormatterOptions(opts)
+	keys := []string{cmp.Or(o.timeKey, "timestamp"), cmp.Or(o.levelKey, "level"), cmp.Or(o.messageKey, "message")}
+	static := dropStaticFields(o.staticFields, func(key string) bool {
+		return slices.Contains(keys, key) || (o.fieldsMode == jsonFieldsNested && key == o.fieldsKey)
+	})
+	return &JsonFormatter{
+		time:           newTimeEncoder(o, time.RFC3339),
+		timePrefix:     "{" + jsonKey(o.timeKey, "timestamp"),
+		levelPrefix:    "," + jsonKey(o.levelKey, "level") + `"`,
+		messagePrefix:  "," + jsonKey(o.messageKey, "message"),
+		fieldsPrefix:   "," + jsonKey(o.fieldsKey, "fields") + "{",
+		lowercaseLevel: o.lowercaseLevel,
+		fieldsMode:     o.fieldsMode,
+		keys:           keys,
+		static:         appendJSONFields(nil, static),
+	}
+}
+
+// jsonKey 返回 "key": 形式的键名片段，key 为空时使用默认键名
+func jsonKey(key, def string) string {
+	if key == "" {
+		key = def
+	}
+	return string(appendJSONString(nil, key)) + ":"
 }
 
 // JsonLogEntry JSON日志条目结构
@@ -64,22 +237,60 @@ type JsonLogEntry struct {
 
 // Format 格式化为JSON格式
 func (f *JsonFormatter) Format(writer io.Writer, level LogLevel, message string) {
-	entry := JsonLogEntry{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Level:     level.String(),
-		Message:   message,
-	}
-	
-	jsonData, err := json.Marshal(entry)
-	if err != nil {
-		// 如果JSON序列化失败，回退到文本格式
-		textFormatter := NewTextFormatter()
-		textFormatter.Format(writer, level, message)
-		return
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为一行 JSON。默认与 JsonLogEntry 结构一致，参数以文本形式附加在 message 中；
+// 配置 WithInlineFields 或 WithFieldsKey 后参数输出为 JSON 字段
+func (f *JsonFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	buf.b = append(buf.b, f.timePrefix...)
+	buf.b = f.time.appendJSON(buf.b, e.Time)
+	buf.b = append(buf.b, f.levelPrefix...)
+	if f.lowercaseLevel {
+		buf.b = appendLowerASCII(buf.b, e.Level.String())
+	} else {
+		buf.b = append(buf.b, e.Level.String()...)
+	}
+	buf.b = append(buf.b, '"')
+	buf.b = append(buf.b, f.messagePrefix...)
+	if f.fieldsMode == jsonFieldsInMessage {
+		buf.b = append(buf.b, '"')
+		buf.b = appendMessageText(buf.b, e, appendJSONEscaped)
+		buf.b = append(buf.b, '"')
+	} else {
+		buf.b = appendJSONString(buf.b, e.Message)
+	}
+	buf.b = append(buf.b, f.static...)
+
+	switch {
+	case f.fieldsMode == jsonFieldsInline:
+		buf.b = f.appendInlineFields(buf.b, e.Fields)
+	case f.fieldsMode == jsonFieldsNested && len(e.Fields) > 0:
+		buf.b = append(buf.b, f.fieldsPrefix...)
+		start := len(buf.b)
+		buf.b = appendJSONFields(buf.b, e.Fields)
+		buf.b = append(buf.b[:start], buf.b[start+1:]...) // 去掉第一个参数前的逗号
+		buf.b = append(buf.b, '}')
+	}
+	buf.b = append(buf.b, '}', '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendInlineFields 以顶层字段追加参数，与时间、级别、消息键同名的参数加 labels. 前缀
+func (f *JsonFormatter) appendInlineFields(dst []byte, fields []Field) []byte {
+	positional := 0
+	for _, field := range fields {
+		dst = append(dst, ',', '"')
+		if slices.Contains(f.keys, field.Key) {
+			dst = append(dst, "labels."...)
+		}
+		dst = appendFieldKey(dst, field, &positional, appendJSONEscaped)
+		dst = append(dst, '"', ':')
+		dst = appendJSONFieldValue(dst, field)
 	}
-	
-	writer.Write(jsonData)
-	writer.Write([]byte("\n"))
+	return dst
 }
 
 // parseLogFormat 解析日志格式字符串
@@ -89,19 +300,67 @@ func parseLogFormat(formatStr string) LogFormat {
 		return JsonFormat
 	case "text":
 		return TextFormat
+	case "logfmt":
+		return LogfmtFormat
+	case "ecs":
+		return ECSFormat
+	case "gcp", "stackdriver":
+		return GCPFormat
+	case "datadog":
+		return DatadogFormat
+	case "cloudwatch", "aws":
+		return CloudWatchFormat
+	case "gelf":
+		return GELFFormat
+	case "syslog", "rfc5424":
+		return SyslogFormat
+	case "otlp", "otel":
+		return OTLPFormat
+	case "binary":
+		return BinaryFormat
+	case "csv":
+		return CSVFormat
+	case "tsv":
+		return TSVFormat
+	case "pretty", "dev":
+		return PrettyFormat
 	default:
 		return TextFormat // 默认返回文本格式
 	}
 }
 
 // createFormatter 根据格式创建对应的格式化器
-func createFormatter(format LogFormat) Formatter {
+func createFormatter(format LogFormat, opts ...FormatterOption) EntryFormatter {
 	switch format {
 	case JsonFormat:
-		return NewJsonFormatter()
+		return NewJsonFormatter(opts...)
 	case TextFormat:
-		return NewTextFormatter()
+		return NewTextFormatter(opts...)
+	case LogfmtFormat:
+		return NewLogfmtFormatter(opts...)
+	case ECSFormat:
+		return NewECSFormatter(opts...)
+	case GCPFormat:
+		return NewCloudFormatter(GCPProfile, opts...)
+	case DatadogFormat:
+		return NewCloudFormatter(DatadogProfile, opts...)
+	case CloudWatchFormat:
+		return NewCloudFormatter(CloudWatchProfile, opts...)
+	case GELFFormat:
+		return NewGELFFormatter(opts...)
+	case SyslogFormat:
+		return NewSyslogFormatter(RFC5424, opts...)
+	case OTLPFormat:
+		return NewOTLPFormatter(opts...)
+	case BinaryFormat:
+		return NewBinaryFormatter(opts...)
+	case CSVFormat:
+		return NewCSVFormatter(opts...)
+	case TSVFormat:
+		return NewTSVFormatter(opts...)
+	case PrettyFormat:
+		return NewPrettyFormatter(opts...)
 	default:
-		return NewTextFormatter()
+		return NewTextFormatter(opts...)
 	}
 }
@@ -3,6 +3,7 @@ package ygggo_log
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"os"
 	"strings"
 	"testing"
@@ -213,3 +214,186 @@ func TestSingletonWithJsonFormat(t *testing.T) {
 		t.Error("GetLogEnv should return the same singleton instance")
 	}
 }
+
+// countingWriter 记录每次 Write 调用的内容
+type countingWriter struct {
+	writes [][]byte
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	cp := make([]byte, len(p))
+	copy(cp, p)
+	w.writes = append(w.writes, cp)
+	return len(p), nil
+}
+
+func TestFormatters_SingleWritePerEntry(t *testing.T) {
+	formatters := []Formatter{NewTextFormatter(), NewJsonFormatter(), NewColorFormatter()}
+
+	for _, f := range formatters {
+		var w countingWriter
+		logger := NewLogger(&w)
+		logger.SetFormatter(f)
+
+		logger.Info("one write", "a=1", map[string]any{"b": true}, 3.5)
+
+		if len(w.writes) != 1 {
+			t.Errorf("Expected exactly one Write for %T, got: %d", f, len(w.writes))
+			continue
+		}
+		if !bytes.HasSuffix(w.writes[0], []byte("\n")) {
+			t.Errorf("Expected the single Write of %T to include the newline: %q", f, w.writes[0])
+		}
+	}
+}
+
+func TestCombinedFormatter_SingleWritePerDestination(t *testing.T) {
+	var console, file countingWriter
+	logger := NewLogger(io.Discard)
+	logger.SetFormatter(NewCombinedFormatter(&console, &file))
+
+	logger.Warning("to both", "k=v")
+
+	if len(console.writes) != 1 || len(file.writes) != 1 {
+		t.Fatalf("Expected one Write per destination, got console=%d file=%d", len(console.writes), len(file.writes))
+	}
+	var entry LogEntry
+	if err := json.Unmarshal(file.writes[0], &entry); err != nil {
+		t.Errorf("File output is not a complete JSON line: %v", err)
+	}
+}
+
+func TestJsonFormatter_KeyNames(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewJsonFormatter(WithTimeKey("ts"), WithLevelKey("severity"), WithMessageKey("msg"), WithLowercaseLevel())
+	formatter.Format(&buf, WarningLevel, "disk low")
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if doc["severity"] != "warning" || doc["msg"] != "disk low" || doc["ts"] == nil {
+		t.Errorf("Unexpected keys or values: %v", doc)
+	}
+	if _, ok := doc["timestamp"]; ok {
+		t.Error("Expected default timestamp key to be renamed")
+	}
+}
+
+func TestJsonFormatter_FieldsModes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     []FormatterOption
+		expected string
+	}{
+		{"message", nil, `"message":"login user=tom 3"}`},
+		{"inline", []FormatterOption{WithInlineFields()}, `"message":"login","user":"tom","arg0":3}`},
+		{"nested", []FormatterOption{WithFieldsKey("fields")}, `"message":"login","fields":{"user":"tom","arg0":3}}`},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		logger := NewLogger(&buf)
+		logger.SetFormatter(NewJsonFormatter(tc.opts...))
+		logger.Info("login", String("user", "tom"), 3)
+
+		if !strings.HasSuffix(strings.TrimSpace(buf.String()), tc.expected) {
+			t.Errorf("%s: expected suffix %s, got: %s", tc.name, tc.expected, buf.String())
+		}
+	}
+}
+
+func TestJsonFormatter_InlineFieldsDoNotCollide(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewJsonFormatter(WithInlineFields(), WithLevelKey("severity")))
+	logger.Info("hello", String("message", "spoof"), String("severity", "x"), String("timestamp", "y"), String("level", "z"))
+
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), `"message":"hello","labels.message":"spoof","labels.severity":"x","labels.timestamp":"y","level":"z"}`) {
+		t.Errorf("Expected colliding fields under labels., got: %s", buf.String())
+	}
+}
+
+func TestJsonFormatter_NestedWithoutFields(t *testing.T) {
+	var buf bytes.Buffer
+	NewJsonFormatter(WithFieldsKey("fields")).Format(&buf, InfoLevel, "plain")
+
+	if strings.Contains(buf.String(), `"fields"`) {
+		t.Errorf("Expected no fields key without fields, got: %s", buf.String())
+	}
+}
+
+func TestJsonFormatter_StaticFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewJsonFormatter(WithStaticFields(String("service", "api"), String("env", "prod")), WithInlineFields()))
+	logger.Info("started", Int("port", 8080))
+
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), `"message":"started","service":"api","env":"prod","port":8080}`) {
+		t.Errorf("Expected static fields before log fields, got: %s", buf.String())
+	}
+}
+
+func TestJsonFormatter_StaticFieldsDoNotCollide(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewJsonFormatter(WithStaticFields(String("level", "x"), String("msg", "x"), String("env", "prod")), WithMessageKey("msg")))
+	logger.Info("started")
+
+	if strings.Count(buf.String(), `"level":`) != 1 || strings.Count(buf.String(), `"msg":`) != 1 {
+		t.Errorf("Expected static fields named like built-in keys to be dropped, got: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"env":"prod"`) {
+		t.Errorf("Expected other static fields to be kept, got: %s", buf.String())
+	}
+}
+
+func TestLoadConfigFromEnv_JsonLayout(t *testing.T) {
+	env := map[string]string{
+		"YGGGO_LOG_FORMAT":          "json",
+		"YGGGO_LOG_TIME_KEY":        "ts",
+		"YGGGO_LOG_LEVEL_KEY":       "severity",
+		"YGGGO_LOG_MESSAGE_KEY":     "msg",
+		"YGGGO_LOG_LOWERCASE_LEVEL": "true",
+		"YGGGO_LOG_FIELDS_KEY":      "fields",
+	}
+	for key, value := range env {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	var buf bytes.Buffer
+	NewLoggerFromEnvWithOutput(&buf).Warning("disk low", String("path", "/data"))
+	if !strings.HasPrefix(buf.String(), `{"ts":`) || !strings.HasSuffix(strings.TrimSpace(buf.String()), `,"severity":"warning","msg":"disk low","fields":{"path":"/data"}}`) {
+		t.Errorf("Unexpected JSON layout: %s", buf.String())
+	}
+
+	os.Unsetenv("YGGGO_LOG_FIELDS_KEY")
+	os.Setenv("YGGGO_LOG_INLINE_FIELDS", "true")
+	defer os.Unsetenv("YGGGO_LOG_INLINE_FIELDS")
+	buf.Reset()
+	NewLoggerFromEnvWithOutput(&buf).Warning("disk low", String("path", "/data"))
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), `"msg":"disk low","path":"/data"}`) {
+		t.Errorf("Expected inline fields, got: %s", buf.String())
+	}
+}
+
+func TestLoadConfigFromEnv_StaticFields(t *testing.T) {
+	os.Setenv("YGGGO_LOG_STATIC_FIELDS", "service=api, env=prod,,=x,version=")
+	defer os.Unsetenv("YGGGO_LOG_STATIC_FIELDS")
+
+	config := LoadConfigFromEnv()
+	if len(config.StaticFields) != 3 {
+		t.Fatalf("Expected 3 static fields, got: %v", config.StaticFields)
+	}
+	if config.StaticFields[1].Key != "env" || config.StaticFields[1].String() != "prod" {
+		t.Errorf("Unexpected static field: %+v", config.StaticFields[1])
+	}
+
+	var buf bytes.Buffer
+	os.Setenv("YGGGO_LOG_FORMAT", "logfmt")
+	defer os.Unsetenv("YGGGO_LOG_FORMAT")
+	NewLoggerFromEnvWithOutput(&buf).Info("hi")
+	if !strings.Contains(buf.String(), `msg=hi service=api env=prod version=""`) {
+		t.Errorf("Expected static fields in logfmt output, got: %s", buf.String())
+	}
+}
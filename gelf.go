@@ -0,0 +1,143 @@
+package ygggo_log
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GELFVersion GELFFormatter 输出的 version
+const GELFVersion = "1.1"
+
+// GELFFormatter 输出 Graylog GELF 1.1 消息，每条一行 JSON：
+//
+//	{"version":"1.1","host":"web-1","short_message":"...","timestamp":1717000000.123,
+//	 "level":6,"_file":"main.go","_line":12,"_user":"tom"}
+//
+// 消息包含多行时，short_message 为第一行，full_message 为完整消息。
+// 日志参数和常量字段作为以 _ 开头的附加字段输出，键名中 [A-Za-z0-9_.-] 以外的字符替换为 _，
+// 保留键名 id 输出为 _id_；值只能是字符串或数字，布尔值等其他类型输出为字符串。
+// 发送到 Graylog 可配合 GELFWriter 使用。
+type GELFFormatter struct {
+	host   string
+	static []byte // 预先编码的常量字段
+}
+
+// NewGELFFormatter 创建 GELF 格式化器。timestamp 固定为带毫秒的 Unix 秒数，时间布局配置不生效
+func NewGELFFormatter(opts ...FormatterOption) *GELFFormatter {
+	o := newFormatterOptions(opts)
+	return &GELFFormatter{
+		host:   hostname(),
+		static: appendGELFFields(nil, o.staticFields),
+	}
+}
+
+// Format 格式化为 GELF JSON
+func (f *GELFFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为 GELF JSON
+func (f *GELFFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	buf.b = append(buf.b, `{"version":"`+GELFVersion+`","host":`...)
+	buf.b = appendJSONString(buf.b, f.host)
+
+	short, multiline := e.Message, false
+	if i := strings.IndexByte(short, '\n'); i >= 0 {
+		short, multiline = strings.TrimRight(short[:i], "\r"), true
+	}
+	buf.b = append(buf.b, `,"short_message":`...)
+	buf.b = appendJSONString(buf.b, short)
+	if multiline {
+		buf.b = append(buf.b, `,"full_message":`...)
+		buf.b = appendJSONString(buf.b, e.Message)
+	}
+
+	ms := e.Time.UnixMilli()
+	buf.b = append(buf.b, `,"timestamp":`...)
+	buf.b = strconv.AppendInt(buf.b, ms/1000, 10)
+	buf.b = append(buf.b, '.')
+	frac := ms % 1000
+	buf.b = append(buf.b, byte('0'+frac/100), byte('0'+frac/10%10), byte('0'+frac%10))
+
+	buf.b = append(buf.b, `,"level":`...)
+	buf.b = strconv.AppendInt(buf.b, int64(syslogSeverity(e.Level)), 10)
+
+	file, line := e.Caller()
+	buf.b = append(buf.b, `,"_file":`...)
+	buf.b = appendJSONString(buf.b, file)
+	buf.b = append(buf.b, `,"_line":`...)
+	buf.b = strconv.AppendInt(buf.b, int64(line), 10)
+
+	buf.b = append(buf.b, f.static...)
+	buf.b = appendGELFFields(buf.b, e.Fields)
+	buf.b = append(buf.b, '}', '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendGELFFields 以附加字段的形式追加参数，每个参数前都带逗号
+func appendGELFFields(dst []byte, fields []Field) []byte {
+	positional := 0
+	for _, field := range fields {
+		dst = append(dst, `,"_`...)
+		dst = appendFieldKey(dst, field, &positional, appendGELFKey)
+		dst = append(dst, '"', ':')
+		dst = appendGELFValue(dst, field)
+	}
+	return dst
+}
+
+// appendGELFKey 追加附加字段名（不含 _ 前缀），非法字符替换为 _，保留键名 id 改为 id_
+func appendGELFKey(dst []byte, key string) []byte {
+	if key == "id" {
+		return append(dst, "id_"...)
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_', c == '.', c == '-':
+			dst = append(dst, c)
+		default:
+			dst = append(dst, '_')
+		}
+	}
+	return dst
+}
+
+// appendGELFValue 追加附加字段的值：有限数值输出为数字，其余输出为字符串
+func appendGELFValue(dst []byte, f Field) []byte {
+	switch f.Type {
+	case IntType, UintType:
+		return appendFieldValue(dst, f)
+	case FloatType:
+		if v := f.float(); !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return appendFieldValue(dst, f)
+		}
+	case StringType:
+		return appendJSONString(dst, f.str)
+	}
+	dst = append(dst, '"')
+	dst = appendFieldValueFunc(dst, f, appendJSONEscaped)
+	return append(dst, '"')
+}
+
+// syslogSeverity 将日志级别映射为 syslog 严重程度（RFC 5424），GELF 的 level 与之相同
+func syslogSeverity(level LogLevel) int {
+	switch level {
+	case DebugLevel:
+		return 7 // debug
+	case InfoLevel:
+		return 6 // informational
+	case WarningLevel:
+		return 4 // warning
+	case ErrorLevel:
+		return 3 // error
+	case PanicLevel:
+		return 2 // critical
+	default:
+		return 5 // notice
+	}
+}
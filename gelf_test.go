@@ -0,0 +1,76 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestGELFFormatter_Fields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewGELFFormatter(WithStaticFields(String("env", "prod"))))
+
+	logger.Warning("disk low", String("user id", "tom"), Int("id", 7), Bool("ok", true), math.Inf(1), errors.New("boom"))
+
+	doc := decodeLine(t, buf.Bytes())
+	if doc["version"] != "1.1" || doc["short_message"] != "disk low" {
+		t.Errorf("Unexpected version/short_message: %v", doc)
+	}
+	if _, ok := doc["full_message"]; ok {
+		t.Error("Expected no full_message for a single-line message")
+	}
+	if doc["level"] != float64(4) {
+		t.Errorf("Expected WARNING to map to syslog 4, got: %v", doc["level"])
+	}
+	if doc["timestamp"] != float64(fixedClock().UnixMilli())/1000 {
+		t.Errorf("Unexpected timestamp: %v", doc["timestamp"])
+	}
+	if !strings.HasSuffix(doc["_file"].(string), "gelf_test.go") || doc["_line"] == float64(0) {
+		t.Errorf("Unexpected caller: %v:%v", doc["_file"], doc["_line"])
+	}
+	expected := map[string]any{
+		"_env":     "prod",
+		"_user_id": "tom",
+		"_id_":     float64(7),
+		"_ok":      "true",
+		"_arg0":    "+Inf",
+		"_arg1":    "boom",
+	}
+	for key, value := range expected {
+		if doc[key] != value {
+			t.Errorf("Expected %s=%v, got: %v", key, value, doc[key])
+		}
+	}
+	if _, ok := doc["_id"]; ok {
+		t.Error("Expected reserved _id not to be emitted")
+	}
+}
+
+func TestGELFFormatter_Multiline(t *testing.T) {
+	var buf bytes.Buffer
+	NewGELFFormatter().Format(&buf, ErrorLevel, "request failed\r\nstack trace")
+
+	doc := decodeLine(t, buf.Bytes())
+	if doc["short_message"] != "request failed" {
+		t.Errorf("Expected first line as short_message, got: %v", doc["short_message"])
+	}
+	if doc["full_message"] != "request failed\r\nstack trace" {
+		t.Errorf("Expected full message, got: %v", doc["full_message"])
+	}
+	if doc["level"] != float64(3) {
+		t.Errorf("Expected ERROR to map to syslog 3, got: %v", doc["level"])
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	expected := map[LogLevel]int{DebugLevel: 7, InfoLevel: 6, WarningLevel: 4, ErrorLevel: 3, PanicLevel: 2}
+	for level, severity := range expected {
+		if got := syslogSeverity(level); got != severity {
+			t.Errorf("Expected %v to map to %d, got: %d", level, severity, got)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sync"
+)
+
+const (
+	// DefaultGELFChunkSize is the default maximum UDP datagram size, safe for most WAN paths.
+	DefaultGELFChunkSize = 1420
+	// gelfChunkHeaderSize is the size of the chunk header: magic (2), message id (8), seq (1), count (1).
+	gelfChunkHeaderSize = 12
+	// gelfMaxChunks is the maximum number of chunks Graylog accepts for one message.
+	gelfMaxChunks = 128
+)
+
+// ErrGELFMessageTooLarge is returned when a UDP message needs more than 128 chunks.
+var ErrGELFMessageTooLarge = errors.New("gelf: message too large for 128 chunks")
+
+// GELFWriter sends GELF messages to a Graylog input. Each Write must hold one
+// message, as produced by GELFFormatter; a trailing newline is stripped.
+//
+// Over UDP, messages larger than the chunk size are split into GELF chunks and
+// may be gzip-compressed. Over TCP, messages are sent uncompressed and
+// terminated by a null byte, and the connection is re-dialed once on failure.
+// Writes after Close return ErrWriterClosed. GELFWriter is safe for concurrent use.
+type GELFWriter struct {
+	network   string       // "udp" or "tcp"
+	address   string       // host:port of the Graylog input
+	conn      net.Conn     // current connection; nil after a failed TCP write
+	compress  bool         // gzip UDP messages
+	chunkSize int          // maximum UDP datagram size
+	zbuf      bytes.Buffer // reused compression output
+	zw        *gzip.Writer // reused gzip writer
+	closed    bool         // set by Close; later writes fail instead of re-dialing
+	mutex     sync.Mutex   // serializes writes and guards the fields above
+}
+
+// NewGELFWriter dials the Graylog input at address. The network must be
+// "udp" (also "udp4"/"udp6") or "tcp" (also "tcp4"/"tcp6").
+func NewGELFWriter(network, address string) (*GELFWriter, error) {
+	switch network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("gelf: unsupported network %q", network)
+	}
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &GELFWriter{
+		network:   network,
+		address:   address,
+		conn:      conn,
+		chunkSize: DefaultGELFChunkSize,
+	}, nil
+}
+
+// SetCompression enables or disables gzip compression of UDP messages.
+// It has no effect over TCP, where Graylog expects uncompressed messages.
+func (w *GELFWriter) SetCompression(enabled bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.compress = enabled
+}
+
+// SetChunkSize sets the maximum UDP datagram size, including the 12-byte chunk
+// header. Use 8154 on local networks with a large MTU. Values that leave no
+// room for payload reset it to DefaultGELFChunkSize.
+func (w *GELFWriter) SetChunkSize(size int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if size <= gelfChunkHeaderSize {
+		size = DefaultGELFChunkSize
+	}
+	w.chunkSize = size
+}
+
+// Write sends one GELF message.
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	msg := bytes.TrimRight(p, "\r\n")
+	if len(msg) == 0 {
+		return len(p), nil
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+
+	var err error
+	if w.isTCP() {
+		err = w.writeTCP(msg)
+	} else {
+		err = w.writeUDP(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the underlying connection. Later writes return ErrWriterClosed.
+func (w *GELFWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *GELFWriter) isTCP() bool {
+	return w.network[:3] == "tcp"
+}
+
+// writeTCP sends msg followed by a null byte, re-dialing once if the write fails.
+func (w *GELFWriter) writeTCP(msg []byte) error {
+	frame := make([]byte, len(msg)+1)
+	copy(frame, msg)
+
+	for attempt := 0; ; attempt++ {
+		if w.conn == nil {
+			conn, err := net.Dial(w.network, w.address)
+			if err != nil {
+				return err
+			}
+			w.conn = conn
+		}
+		_, err := w.conn.Write(frame)
+		if err == nil {
+			return nil
+		}
+		w.conn.Close()
+		w.conn = nil
+		if attempt > 0 {
+			return err
+		}
+	}
+}
+
+// writeUDP sends msg as a single datagram or as GELF chunks, compressing it first when enabled.
+func (w *GELFWriter) writeUDP(msg []byte) error {
+	if w.conn == nil {
+		return net.ErrClosed
+	}
+	if w.compress {
+		w.zbuf.Reset()
+		if w.zw == nil {
+			w.zw = gzip.NewWriter(&w.zbuf)
+		} else {
+			w.zw.Reset(&w.zbuf)
+		}
+		w.zw.Write(msg)
+		if err := w.zw.Close(); err != nil {
+			return err
+		}
+		msg = w.zbuf.Bytes()
+	}
+
+	if len(msg) <= w.chunkSize {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+
+	payload := w.chunkSize - gelfChunkHeaderSize
+	count := (len(msg) + payload - 1) / payload
+	if count > gelfMaxChunks {
+		return ErrGELFMessageTooLarge
+	}
+
+	chunk := make([]byte, 0, w.chunkSize)
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	for seq := 0; seq < count; seq++ {
+		end := min((seq+1)*payload, len(msg))
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(seq), byte(count))
+		chunk = append(chunk, msg[seq*payload:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
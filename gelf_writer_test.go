@@ -0,0 +1,199 @@
+package ygggo_log
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readGELFChunks 读取一条被分块的 GELF 消息并按序号重组
+func readGELFChunks(t *testing.T, pc net.PacketConn) []byte {
+	t.Helper()
+	type chunk struct {
+		seq  int
+		data []byte
+	}
+	var chunks []chunk
+	var id []byte
+	count := -1
+	buf := make([]byte, 65536)
+	for count < 0 || len(chunks) < count {
+		pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Failed to read chunk: %v", err)
+		}
+		p := buf[:n]
+		if n < gelfChunkHeaderSize || p[0] != 0x1e || p[1] != 0x0f {
+			t.Fatalf("Expected a GELF chunk, got: %x", p)
+		}
+		if id == nil {
+			id = append([]byte(nil), p[2:10]...)
+		} else if !bytes.Equal(id, p[2:10]) {
+			t.Fatalf("Chunks carry different message ids")
+		}
+		count = int(p[11])
+		chunks = append(chunks, chunk{seq: int(p[10]), data: append([]byte(nil), p[12:]...)})
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].seq < chunks[j].seq })
+	var msg []byte
+	for _, c := range chunks {
+		msg = append(msg, c.data...)
+	}
+	return msg
+}
+
+func TestGELFWriter_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer pc.Close()
+
+	w, err := NewGELFWriter("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to create GELF writer: %v", err)
+	}
+	defer w.Close()
+
+	logger := NewLogger(w)
+	logger.SetFormatter(NewGELFFormatter())
+	logger.Info("hello graylog", String("user", "tom"))
+
+	buf := make([]byte, 65536)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Failed to read datagram: %v", err)
+	}
+	if buf[n-1] == '\n' {
+		t.Error("Expected trailing newline to be stripped")
+	}
+	doc := decodeLine(t, buf[:n])
+	if doc["short_message"] != "hello graylog" || doc["_user"] != "tom" {
+		t.Errorf("Unexpected message: %v", doc)
+	}
+}
+
+func TestGELFWriter_UDPChunkedGzip(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer pc.Close()
+
+	w, err := NewGELFWriter("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to create GELF writer: %v", err)
+	}
+	defer w.Close()
+	w.SetCompression(true)
+	w.SetChunkSize(64)
+
+	// 随机性足够的长消息，压缩后仍需要分块
+	var sb strings.Builder
+	for i := 0; i < 200; i++ {
+		sb.WriteString(time.Duration(i * 7919).String())
+	}
+	logger := NewLogger(w)
+	logger.SetFormatter(NewGELFFormatter())
+	logger.Info(sb.String())
+
+	zr, err := gzip.NewReader(bytes.NewReader(readGELFChunks(t, pc)))
+	if err != nil {
+		t.Fatalf("Expected gzip payload: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	doc := decodeLine(t, data)
+	if doc["short_message"] != sb.String() {
+		t.Errorf("Reassembled message does not match")
+	}
+}
+
+func TestGELFWriter_UDPTooLarge(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer pc.Close()
+
+	w, _ := NewGELFWriter("udp", pc.LocalAddr().String())
+	defer w.Close()
+	w.SetChunkSize(gelfChunkHeaderSize + 1)
+
+	if _, err := w.Write(bytes.Repeat([]byte("x"), gelfMaxChunks+1)); !errors.Is(err, ErrGELFMessageTooLarge) {
+		t.Errorf("Expected ErrGELFMessageTooLarge, got: %v", err)
+	}
+}
+
+func TestGELFWriter_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("TCP not available: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var frames []string
+		for len(frames) < 2 {
+			frame, err := r.ReadString(0)
+			if err != nil {
+				break
+			}
+			frames = append(frames, strings.TrimSuffix(frame, "\x00"))
+		}
+		received <- frames
+	}()
+
+	w, err := NewGELFWriter("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to create GELF writer: %v", err)
+	}
+	defer w.Close()
+	w.SetCompression(true) // TCP 忽略压缩
+
+	logger := NewLogger(w)
+	logger.SetFormatter(NewGELFFormatter())
+	logger.Info("first")
+	logger.Error("second")
+
+	select {
+	case frames := <-received:
+		if len(frames) != 2 {
+			t.Fatalf("Expected 2 null-delimited frames, got: %q", frames)
+		}
+		if doc := decodeLine(t, []byte(frames[1])); doc["short_message"] != "second" {
+			t.Errorf("Unexpected second frame: %v", doc)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for TCP frames")
+	}
+	w.Close()
+	if _, err := w.Write([]byte(`{"short_message":"late"}`)); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed after Close, got: %v", err)
+	}
+}
+
+func TestNewGELFWriter_UnsupportedNetwork(t *testing.T) {
+	if _, err := NewGELFWriter("unix", "/tmp/graylog.sock"); err == nil {
+		t.Error("Expected an error for an unsupported network")
+	}
+}
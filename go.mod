@@ -2,4 +2,7 @@ module github.com/yggai/ygggo_log
 
 go 1.24.5
 
-require github.com/yggai/ygggo_env v1.0.0
+require (
+	github.com/yggai/ygggo_env v1.0.0
+	golang.org/x/sys v0.41.0
+)
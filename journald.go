@@ -0,0 +1,145 @@
+package ygggo_log
+
+import (
+	"encoding/binary"
+	"io"
+	"strconv"
+)
+
+// JournaldFormatter 按 systemd-journald 原生协议编码日志，每条日志为一组 FIELD=value 行：
+//
+//	MESSAGE=user login
+//	PRIORITY=6
+//	SYSLOG_IDENTIFIER=api
+//	CODE_FILE=/src/app/main.go
+//	CODE_LINE=12
+//	CODE_FUNC=main.main
+//	USER=tom
+//
+// 日志参数和常量字段作为 journal 字段输出，键名转为大写，[A-Z0-9_] 以外的字符替换为 _，
+// 并去掉开头的 _（journald 保留的可信字段）；位置参数依次命名为 ARG0、ARG1……
+// 含换行的值使用协议的二进制长度格式。需配合 JournaldWriter 写入 journald 的 socket，
+// 之后可以用 journalctl -o json 查看结构化字段。
+type JournaldFormatter struct {
+	identifier string
+	static     []Field
+}
+
+// NewJournaldFormatter 创建 journald 格式化器，SYSLOG_IDENTIFIER 通过 WithServiceName 设置，
+// 默认取可执行文件名；时间由 journald 记录，时间配置不生效
+func NewJournaldFormatter(opts ...FormatterOption) *JournaldFormatter {
+	o := newFormatterOptions(opts)
+	return &JournaldFormatter{
+		identifier: o.service(),
+		static:     o.staticFields,
+	}
+}
+
+// Format 按 journald 原生协议格式化
+func (f *JournaldFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 按 journald 原生协议格式化
+func (f *JournaldFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	buf.b = appendJournalString(buf.b, "MESSAGE", e.Message)
+
+	buf.b = append(buf.b, "PRIORITY="...)
+	buf.b = strconv.AppendInt(buf.b, int64(syslogSeverity(e.Level)), 10)
+	buf.b = append(buf.b, '\n')
+	buf.b = appendJournalString(buf.b, "SYSLOG_IDENTIFIER", f.identifier)
+
+	frame := e.CallerFrame()
+	if frame.File != "" {
+		buf.b = appendJournalString(buf.b, "CODE_FILE", frame.File)
+		buf.b = append(buf.b, "CODE_LINE="...)
+		buf.b = strconv.AppendInt(buf.b, int64(frame.Line), 10)
+		buf.b = append(buf.b, '\n')
+		buf.b = appendJournalString(buf.b, "CODE_FUNC", frame.Function)
+	}
+
+	buf.b = appendJournalFields(buf.b, f.static)
+	buf.b = appendJournalFields(buf.b, e.Fields)
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendJournalFields 以 journal 字段的形式追加参数
+func appendJournalFields(dst []byte, fields []Field) []byte {
+	positional := 0
+	for _, field := range fields {
+		start := len(dst)
+		dst = appendFieldKey(dst, field, &positional, appendJournalName)
+		for i := start; i < len(dst); i++ {
+			if 'a' <= dst[i] && dst[i] <= 'z' {
+				dst[i] -= 'a' - 'A' // 位置参数 argN
+			}
+		}
+		if len(dst) == start {
+			dst = append(dst, "FIELD"...)
+		}
+		if len(dst)-start > 64 {
+			dst = dst[:start+64]
+		}
+		eq := len(dst)
+		dst = append(dst, '=')
+		dst = appendFieldValue(dst, field)
+		dst = finishJournalField(dst, eq)
+	}
+	return dst
+}
+
+// appendJournalString 追加名称已合法的字段
+func appendJournalString(dst []byte, name, value string) []byte {
+	dst = append(dst, name...)
+	eq := len(dst)
+	dst = append(dst, '=')
+	dst = append(dst, value...)
+	return finishJournalField(dst, eq)
+}
+
+// finishJournalField 结束 dst[eq] 处的 = 之后的字段值；值含换行时改写为
+// NAME\n<64 位小端长度><值>\n 的二进制格式
+func finishJournalField(dst []byte, eq int) []byte {
+	value := dst[eq+1:]
+	binaryForm := false
+	for _, c := range value {
+		if c == '\n' {
+			binaryForm = true
+			break
+		}
+	}
+	if !binaryForm {
+		return append(dst, '\n')
+	}
+	n := len(value)
+	dst = append(dst, make([]byte, 8)...)
+	copy(dst[eq+9:], dst[eq+1:eq+1+n])
+	dst[eq] = '\n'
+	binary.LittleEndian.PutUint64(dst[eq+1:eq+9], uint64(n))
+	return append(dst, '\n')
+}
+
+// appendJournalName 追加字段名：转为大写，非法字符替换为 _，去掉开头的 _，以数字开头时加 F 前缀
+func appendJournalName(dst []byte, name string) []byte {
+	start := len(dst)
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case 'a' <= c && c <= 'z':
+			c -= 'a' - 'A'
+		case 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		default:
+			c = '_'
+		}
+		if c == '_' && len(dst) == start {
+			continue
+		}
+		if '0' <= c && c <= '9' && len(dst) == start {
+			dst = append(dst, 'F')
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
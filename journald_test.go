@@ -0,0 +1,99 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestJournaldFormatter_Fields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewJournaldFormatter(WithServiceName("api"), WithStaticFields(String("env", "prod"))))
+
+	logger.Warning("user login", String("user", "tom"), Int("http.status", 200), String("_hidden", "x"), String("9lives", "y"), String("日志", "z"), 3.5)
+
+	out := buf.String()
+	for _, line := range []string{
+		"MESSAGE=user login\n",
+		"PRIORITY=4\n",
+		"SYSLOG_IDENTIFIER=api\n",
+		"CODE_LINE=",
+		"ENV=prod\n",
+		"USER=tom\n",
+		"HTTP_STATUS=200\n",
+		"HIDDEN=x\n",
+		"F9LIVES=y\n",
+		"FIELD=z\n",
+		"ARG0=3.5\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected %q in output:\n%s", line, out)
+		}
+	}
+	if !strings.Contains(out, "CODE_FILE=") || !strings.Contains(out, "journald_test.go\n") {
+		t.Errorf("Expected CODE_FILE to point to the test file:\n%s", out)
+	}
+	if !strings.Contains(out, "CODE_FUNC=github.com/yggai/ygggo_log.TestJournaldFormatter_Fields\n") {
+		t.Errorf("Expected CODE_FUNC of the caller:\n%s", out)
+	}
+}
+
+func TestJournaldFormatter_MultilineValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewJournaldFormatter())
+
+	logger.Error("line one\nline two", String("stack", "a\nb"))
+
+	out := buf.Bytes()
+	message := []byte("MESSAGE\n")
+	if !bytes.HasPrefix(out, message) {
+		t.Fatalf("Expected binary MESSAGE field, got: %q", out)
+	}
+	size := binary.LittleEndian.Uint64(out[len(message):])
+	value := out[len(message)+8 : len(message)+8+int(size)]
+	if string(value) != "line one\nline two" || out[len(message)+8+int(size)] != '\n' {
+		t.Errorf("Unexpected binary value: %q", value)
+	}
+	if !bytes.Contains(out, []byte("STACK\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n")) {
+		t.Errorf("Expected binary STACK field, got: %q", out)
+	}
+}
+
+func TestAppendJournalName(t *testing.T) {
+	testCases := map[string]string{
+		"user":      "USER",
+		"user-id":   "USER_ID",
+		"__private": "PRIVATE",
+		"1st":       "F1ST",
+		"日志":        "",
+	}
+	for name, expected := range testCases {
+		if got := string(appendJournalName(nil, name)); got != expected {
+			t.Errorf("Expected %s -> %s, got: %s", name, expected, got)
+		}
+	}
+}
+
+func TestLoadConfigFromEnv_Journald(t *testing.T) {
+	os.Setenv("YGGGO_LOG_JOURNALD", "true")
+	defer os.Unsetenv("YGGGO_LOG_JOURNALD")
+
+	config := LoadConfigFromEnv()
+	if !config.Journald {
+		t.Fatal("Expected Journald to be enabled")
+	}
+
+	config.OutputFile = filepath.Join(t.TempDir(), "app.log")
+	combined := NewLoggerFromConfig(config).formatter.(*CombinedFormatter)
+	_, socketErr := os.Stat(DefaultJournaldSocket)
+	_, isJournald := combined.consoleFormatter.(*JournaldFormatter)
+	if isJournald != (socketErr == nil && runtime.GOOS == "linux") {
+		t.Errorf("Expected journald console only when its socket exists, got: %T", combined.consoleFormatter)
+	}
+}
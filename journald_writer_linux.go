@@ -0,0 +1,146 @@
+package ygggo_log
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// DefaultJournaldSocket is the native protocol socket of systemd-journald.
+const DefaultJournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldWriter sends entries encoded by JournaldFormatter to systemd-journald
+// over its unix datagram socket. Each Write must hold one entry.
+//
+// Entries too large for a single datagram are written to a sealed memfd whose
+// descriptor is passed to journald instead, as sd_journal_send does. On kernels
+// without memfd an unlinked temporary file in /dev/shm (or the system temp
+// directory) is passed. Writes after Close return ErrWriterClosed.
+// JournaldWriter is safe for concurrent use.
+type JournaldWriter struct {
+	conn  *net.UnixConn // unconnected datagram socket
+	addr  *net.UnixAddr // journald socket address
+	mutex sync.Mutex    // guards conn
+}
+
+// NewJournaldWriter opens a datagram socket for journald. An empty path uses
+// DefaultJournaldSocket. It fails when the socket does not exist, so callers
+// can fall back to console output outside systemd.
+func NewJournaldWriter(path string) (*JournaldWriter, error) {
+	if path == "" {
+		path = DefaultJournaldSocket
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	fd, err := syscall.Socket(syscall.AF_UNIX, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), "journald")
+	c, err := net.FileConn(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	conn := c.(*net.UnixConn)
+	conn.SetWriteBuffer(8 << 20) // same as sd_journal_send; the kernel caps it at wmem_max
+	return &JournaldWriter{
+		conn: conn,
+		addr: &net.UnixAddr{Name: path, Net: "unixgram"},
+	}, nil
+}
+
+// Write sends one journal entry.
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.conn == nil {
+		return 0, ErrWriterClosed
+	}
+
+	_, _, err := w.conn.WriteMsgUnix(p, nil, w.addr)
+	if err == nil {
+		return len(p), nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return 0, err
+	}
+	if err := w.writeFile(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeFile passes p to journald through the descriptor of a sealed memfd,
+// or of an unlinked temporary file when memfd is unavailable.
+func (w *JournaldWriter) writeFile(p []byte) error {
+	f, err := journalMemfd(p)
+	if err != nil {
+		f, err = journalTempFile(p)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, _, err = w.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), w.addr)
+	return err
+}
+
+// journalMemfd returns a memfd holding p, sealed against further changes as
+// journald requires for memfds.
+func journalMemfd(p []byte) (*os.File, error) {
+	fd, err := unix.MemfdCreate("journal-data", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), "journal-data")
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		return nil, err
+	}
+	seals := unix.F_SEAL_SEAL | unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// journalTempFile returns an unlinked temporary file holding p, in /dev/shm
+// when it exists and in the system temp directory otherwise.
+func journalTempFile(p []byte) (*os.File, error) {
+	dir := "/dev/shm"
+	if _, err := os.Stat(dir); err != nil {
+		dir = os.TempDir()
+	}
+	f, err := os.CreateTemp(dir, "journal-")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name())
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Close closes the socket.
+func (w *JournaldWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,121 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// listenJournal 在临时目录创建模拟 journald 的 datagram socket
+func listenJournal(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram not available: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+func TestJournaldWriter_Datagram(t *testing.T) {
+	server, path := listenJournal(t)
+
+	w, err := NewJournaldWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create journald writer: %v", err)
+	}
+	defer w.Close()
+
+	logger := NewLogger(w)
+	logger.SetFormatter(NewJournaldFormatter(WithServiceName("api")))
+	logger.Info("hello journal", String("user", "tom"))
+
+	buf := make([]byte, 65536)
+	server.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := server.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read datagram: %v", err)
+	}
+	if !bytes.HasPrefix(buf[:n], []byte("MESSAGE=hello journal\nPRIORITY=6\n")) || !bytes.Contains(buf[:n], []byte("USER=tom\n")) {
+		t.Errorf("Unexpected datagram: %q", buf[:n])
+	}
+}
+
+func TestJournaldWriter_LargeEntryPassesFile(t *testing.T) {
+	server, path := listenJournal(t)
+	server.SetReadBuffer(8 << 20)
+
+	w, err := NewJournaldWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create journald writer: %v", err)
+	}
+	defer w.Close()
+	w.conn.SetWriteBuffer(64 << 10) // 保证超出单个 datagram 的上限
+
+	entry := append([]byte("MESSAGE="), bytes.Repeat([]byte("x"), 1<<20)...)
+	entry = append(entry, '\n')
+	if _, err := w.Write(entry); err != nil {
+		t.Fatalf("Failed to write large entry: %v", err)
+	}
+
+	buf := make([]byte, 1024)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	server.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, oobn, _, _, err := server.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("Expected an empty payload with a descriptor, got %d bytes", n)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Expected one control message, got: %v %v", msgs, err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("Expected one descriptor, got: %v %v", fds, err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "journal")
+	defer f.Close()
+	f.Seek(0, io.SeekStart)
+	data, err := io.ReadAll(f)
+	if err != nil || !bytes.Equal(data, entry) {
+		t.Errorf("Passed file does not hold the entry (%d bytes, err %v)", len(data), err)
+	}
+	// journald 只接受已封印的 memfd；内核不支持 memfd 时传递的是临时文件
+	if seals, err := unix.FcntlInt(f.Fd(), unix.F_GET_SEALS, 0); err == nil && seals&unix.F_SEAL_WRITE == 0 {
+		t.Errorf("Expected a memfd sealed against writes, got seals: %#x", seals)
+	}
+}
+
+func TestJournaldWriter_WriteAfterClose(t *testing.T) {
+	_, path := listenJournal(t)
+
+	w, err := NewJournaldWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create journald writer: %v", err)
+	}
+	w.Close()
+	if _, err := w.Write([]byte("MESSAGE=late\n")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed after Close, got: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Expected a second Close to succeed, got: %v", err)
+	}
+}
+
+func TestNewJournaldWriter_MissingSocket(t *testing.T) {
+	if _, err := NewJournaldWriter(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error when the journald socket does not exist")
+	}
+}
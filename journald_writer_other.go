@@ -0,0 +1,29 @@
+//go:build !linux
+
+package ygggo_log
+
+import "errors"
+
+// DefaultJournaldSocket is the native protocol socket of systemd-journald.
+const DefaultJournaldSocket = "/run/systemd/journal/socket"
+
+// errJournaldUnsupported is returned on platforms without systemd-journald.
+var errJournaldUnsupported = errors.New("journald: not supported on this platform")
+
+// JournaldWriter sends entries to systemd-journald. It is only available on Linux.
+type JournaldWriter struct{}
+
+// NewJournaldWriter always fails on platforms other than Linux.
+func NewJournaldWriter(path string) (*JournaldWriter, error) {
+	return nil, errJournaldUnsupported
+}
+
+// Write always fails on platforms other than Linux.
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	return 0, errJournaldUnsupported
+}
+
+// Close does nothing on platforms other than Linux.
+func (w *JournaldWriter) Close() error {
+	return nil
+}
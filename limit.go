@@ -0,0 +1,95 @@
+package ygggo_log
+
+import (
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// truncatedFieldsKey 记录被丢弃的参数个数的参数键名
+const truncatedFieldsKey = "truncated_fields"
+
+// Limits 限制单条日志的大小，为 0 的项表示不限制。超长的消息和参数值在 UTF-8 字符边界处截断，
+// 末尾加上 …(truncated 12345 bytes) 标记说明截掉的字节数；超出个数或总大小的参数被丢弃，
+// 并追加 truncated_fields=N 参数说明丢弃的个数。
+//
+// 限制在交给格式化器之前作用于 Entry，因此对所有格式化器都生效，JSON 等结构化格式的输出仍然合法，
+// 截断后的日志也不会再占用 AsyncWriter 队列和轮转文件的空间。
+type Limits struct {
+	MaxMessageSize int // 消息的最大字节数
+	MaxFieldSize   int // 单个参数值的最大字节数，按文本形式计算；数值、布尔、时间等定长的值不截断
+	MaxFields      int // 参数的最大个数
+	MaxEntrySize   int // 消息、参数键名和参数值的总字节数上限，不含时间、级别等格式化器添加的内容
+}
+
+// apply 按限制截断 Entry 的消息和参数
+func (l Limits) apply(e *Entry) {
+	if l == (Limits{}) {
+		return
+	}
+	remaining := math.MaxInt
+	if l.MaxEntrySize > 0 {
+		remaining = l.MaxEntrySize
+	}
+
+	e.Message = truncateText(e.Message, limitSize(l.MaxMessageSize, remaining))
+	remaining -= len(e.Message)
+
+	kept, dropped := 0, 0
+	for _, f := range e.Fields {
+		if l.MaxFields > 0 && kept >= l.MaxFields || remaining <= len(f.Key) {
+			dropped++
+			continue
+		}
+		remaining -= len(f.Key)
+		f, size := truncateField(f, limitSize(l.MaxFieldSize, remaining))
+		remaining -= size
+		e.Fields[kept] = f
+		kept++
+	}
+	if dropped == 0 {
+		return
+	}
+	clear(e.Fields[kept:])
+	e.Fields = append(e.Fields[:kept], Int(truncatedFieldsKey, dropped))
+}
+
+// limitSize 返回单项限制和剩余总量中较小的一个，单项为 0 时只受剩余总量限制
+func limitSize(limit, remaining int) int {
+	if limit > 0 && limit < remaining {
+		return limit
+	}
+	return max(remaining, 0)
+}
+
+// truncateText 将 s 截断到不超过 size 字节并加上截断标记，未超过时原样返回
+func truncateText(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	cut := size
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…(truncated " + strconv.Itoa(len(s)-cut) + " bytes)"
+}
+
+// truncateField 将参数值截断到不超过 size 字节，返回截断后的参数和计入总大小的字节数。
+// 字符串、错误和任意值按文本形式计算，错误和任意值截断后变为字符串参数；
+// 其余定长的值不截断，按 8 字节计算
+func truncateField(f Field, size int) (Field, int) {
+	switch f.Type {
+	case StringType:
+		f.str = truncateText(f.str, size)
+		return f, len(f.str)
+	case ErrorType, AnyType:
+		text := string(appendFieldValue(nil, f))
+		if len(text) <= size {
+			return f, len(text)
+		}
+		f = String(f.Key, truncateText(text, size))
+		return f, len(f.str)
+	default:
+		return f, 8
+	}
+}
@@ -0,0 +1,149 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// limitedJSON 用带限制的 JSON 日志记录一条日志并解析输出
+func limitedJSON(t *testing.T, limits Limits, log func(l *Logger)) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewJsonFormatter(WithInlineFields()))
+	logger.SetLimits(limits)
+	log(logger)
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", buf.String(), err)
+	}
+	return m
+}
+
+func TestLimits_MessageSize(t *testing.T) {
+	m := limitedJSON(t, Limits{MaxMessageSize: 10}, func(l *Logger) {
+		l.Info(strings.Repeat("a", 100))
+	})
+
+	if m["message"] != strings.Repeat("a", 10)+"…(truncated 90 bytes)" {
+		t.Errorf("Unexpected message: %q", m["message"])
+	}
+}
+
+func TestLimits_FieldSize(t *testing.T) {
+	body := strings.Repeat("x", 40<<20)
+	m := limitedJSON(t, Limits{MaxFieldSize: 16}, func(l *Logger) {
+		l.Info("response", String("body", body), String("user", "tom"), Int("status", 200))
+	})
+
+	if m["body"] != strings.Repeat("x", 16)+"…(truncated 41943024 bytes)" {
+		t.Errorf("Unexpected body: %.80q", m["body"])
+	}
+	if m["user"] != "tom" || m["status"] != float64(200) {
+		t.Errorf("Expected short fields to be kept, got: %v", m)
+	}
+}
+
+func TestLimits_FieldSizeUTF8(t *testing.T) {
+	m := limitedJSON(t, Limits{MaxFieldSize: 4}, func(l *Logger) {
+		l.Info("msg", String("name", "中文名字"))
+	})
+
+	// 不在多字节字符中间截断
+	if m["name"] != "中…(truncated 9 bytes)" {
+		t.Errorf("Unexpected value: %q", m["name"])
+	}
+}
+
+func TestLimits_AnyAndError(t *testing.T) {
+	m := limitedJSON(t, Limits{MaxFieldSize: 5}, func(l *Logger) {
+		l.Info("msg", Err(errors.New("connection refused")), Any("ids", []int{1, 2, 3}), Any("small", 1))
+	})
+
+	if m["error"] != "conne…(truncated 13 bytes)" {
+		t.Errorf("Unexpected error: %q", m["error"])
+	}
+	if m["ids"] != "[1 2 …(truncated 2 bytes)" {
+		t.Errorf("Unexpected ids: %q", m["ids"])
+	}
+	if m["small"] != float64(1) {
+		t.Errorf("Expected short values to keep their type, got: %v", m["small"])
+	}
+}
+
+func TestLimits_MaxFields(t *testing.T) {
+	m := limitedJSON(t, Limits{MaxFields: 2}, func(l *Logger) {
+		l.Info("msg", "a=1", "b=2", "c=3", "d=4")
+	})
+
+	if m["a"] != "1" || m["b"] != "2" || m["c"] != nil || m["truncated_fields"] != float64(2) {
+		t.Errorf("Unexpected fields: %v", m)
+	}
+}
+
+func TestLimits_EntrySize(t *testing.T) {
+	m := limitedJSON(t, Limits{MaxEntrySize: 20}, func(l *Logger) {
+		l.Info("hello", String("a", "0123456789"), String("b", "0123456789"), String("c", "x"))
+	})
+
+	// hello(5) + a(1) + 0123456789(10) 之后只剩 4 字节：b 的值截断为 3 字节，c 被丢弃
+	if m["a"] != "0123456789" || m["b"] != "012…(truncated 7 bytes)" || m["c"] != nil || m["truncated_fields"] != float64(1) {
+		t.Errorf("Unexpected fields: %v", m)
+	}
+}
+
+func TestLimits_TextFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetLimits(Limits{MaxFieldSize: 3})
+	logger.Info("msg", String("v", "abcdef"))
+
+	if !strings.HasSuffix(buf.String(), `v="abc…(truncated 3 bytes)"`+"\n") {
+		t.Errorf("Unexpected output: %q", buf.String())
+	}
+}
+
+func TestLoadConfigFromEnv_Limits(t *testing.T) {
+	config := LoadConfigFromEnv()
+	if config.Limits != (Limits{MaxFieldSize: 64 << 10, MaxEntrySize: 1 << 20}) {
+		t.Errorf("Unexpected default limits: %+v", config.Limits)
+	}
+
+	os.Setenv("YGGGO_LOG_MAX_MESSAGE_SIZE", "4KB")
+	os.Setenv("YGGGO_LOG_MAX_FIELD_SIZE", "0")
+	os.Setenv("YGGGO_LOG_MAX_FIELDS", "20")
+	os.Setenv("YGGGO_LOG_MAX_ENTRY_SIZE", "off")
+	defer func() {
+		os.Unsetenv("YGGGO_LOG_MAX_MESSAGE_SIZE")
+		os.Unsetenv("YGGGO_LOG_MAX_FIELD_SIZE")
+		os.Unsetenv("YGGGO_LOG_MAX_FIELDS")
+		os.Unsetenv("YGGGO_LOG_MAX_ENTRY_SIZE")
+	}()
+
+	config = LoadConfigFromEnv()
+	if config.Limits != (Limits{MaxMessageSize: 4 << 10, MaxFields: 20}) {
+		t.Errorf("Unexpected limits: %+v", config.Limits)
+	}
+	// 无效值关闭该限制并记录配置错误，而不是变成 100MB
+	if len(config.Errors) != 1 || !strings.Contains(config.Errors[0].Error(), "YGGGO_LOG_MAX_ENTRY_SIZE") {
+		t.Errorf("Expected a config error for the invalid entry size, got: %v", config.Errors)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidMaxFields(t *testing.T) {
+	for _, value := range []string{"1K", "-5", "many", "2.5"} {
+		t.Setenv("YGGGO_LOG_MAX_FIELDS", value)
+		config := LoadConfigFromEnv()
+		if config.Limits.MaxFields != 0 {
+			t.Errorf("Expected %q to disable the field count limit, got: %d", value, config.Limits.MaxFields)
+		}
+		if len(config.Errors) != 1 || !strings.Contains(config.Errors[0].Error(), "YGGGO_LOG_MAX_FIELDS") {
+			t.Errorf("Expected a config error for %q, got: %v", value, config.Errors)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package ygggo_log
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 常用编辑器的调用位置链接模板
+const (
+	VSCodeLinkTemplate = "vscode://file{abs}:{line}"
+	CursorLinkTemplate = "cursor://file{abs}:{line}"
+	IdeaLinkTemplate   = "idea://open?file={abs}&line={line}"
+	FileLinkTemplate   = "file://{abs}"
+)
+
+// WithCallerLink 让 ColorFormatter 和 PrettyFormatter 把调用位置渲染为 OSC 8 终端超链接，
+// 显示的文本不变，点击后打开模板生成的地址。模板中的占位符：
+//   - {abs}      源文件的绝对路径，以 / 分隔并以 / 开头（Windows 上为 /C:/...）
+//   - {path}     相对于仓库根目录（包含 .git 的目录，找不到时为 go.mod 所在目录）的路径
+//   - {file}     文件名
+//   - {line}     行号
+//   - {func}     函数名
+//   - {revision} 构建时的 VCS 版本（debug.ReadBuildInfo 的 vcs.revision），未知时为 HEAD
+//
+// 例如 VSCodeLinkTemplate，或仓库网页地址
+// https://github.com/org/repo/blob/{revision}/{path}#L{line}。
+// 模板为空时不输出链接；名称 vscode、cursor、idea、file 对应内置模板
+func WithCallerLink(template string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.callerLink = template
+	}
+}
+
+// WithCallerPath 让 ColorFormatter 和 PrettyFormatter 把调用位置显示为相对于仓库根目录的路径
+// （与链接模板中的 {path} 相同，如 internal/api/user.go:42），而不是文件名。
+// 适用于不支持 OSC 8 超链接的终端，同名文件较多时也能区分调用位置
+func WithCallerPath() FormatterOption {
+	return func(o *formatterOptions) {
+		o.callerPath = true
+	}
+}
+
+// linkVar 链接模板中的占位符
+type linkVar uint8
+
+const (
+	linkLiteral linkVar = iota
+	linkAbs
+	linkPath
+	linkFile
+	linkLine
+	linkFunc
+	linkRevision
+)
+
+// linkSegment 链接模板解析后的一个片段
+type linkSegment struct {
+	v    linkVar
+	text string
+}
+
+// callerLink 解析后的链接模板，nil 表示不输出链接
+type callerLink struct {
+	segments []linkSegment
+}
+
+// newCallerLink 解析链接模板，模板为空时返回 nil；无法识别的占位符原样输出
+func newCallerLink(template string) *callerLink {
+	switch strings.ToLower(strings.TrimSpace(template)) {
+	case "":
+		return nil
+	case "vscode":
+		template = VSCodeLinkTemplate
+	case "cursor":
+		template = CursorLinkTemplate
+	case "idea", "goland":
+		template = IdeaLinkTemplate
+	case "file":
+		template = FileLinkTemplate
+	}
+
+	vars := map[string]linkVar{
+		"{abs}": linkAbs, "{path}": linkPath, "{file}": linkFile,
+		"{line}": linkLine, "{func}": linkFunc, "{revision}": linkRevision,
+	}
+	l := &callerLink{}
+	for template != "" {
+		i := strings.IndexByte(template, '{')
+		j := strings.IndexByte(template[max(i, 0):], '}')
+		if i < 0 || j < 0 {
+			l.segments = append(l.segments, linkSegment{v: linkLiteral, text: template})
+			break
+		}
+		name := template[i : i+j+1]
+		v, ok := vars[name]
+		if !ok {
+			l.segments = append(l.segments, linkSegment{v: linkLiteral, text: template[:i+j+1]})
+		} else {
+			if i > 0 {
+				l.segments = append(l.segments, linkSegment{v: linkLiteral, text: template[:i]})
+			}
+			l.segments = append(l.segments, linkSegment{v: v})
+		}
+		template = template[i+j+1:]
+	}
+	return l
+}
+
+// appendOpen 追加 OSC 8 链接的起始序列；调用位置未知时不输出
+func (l *callerLink) appendOpen(dst []byte, frame runtime.Frame) []byte {
+	if l == nil || frame.File == "" {
+		return dst
+	}
+	dst = append(dst, "\033]8;;"...)
+	for _, seg := range l.segments {
+		switch seg.v {
+		case linkLiteral:
+			dst = append(dst, seg.text...)
+		case linkAbs:
+			abs := filepath.ToSlash(frame.File)
+			if !strings.HasPrefix(abs, "/") {
+				dst = append(dst, '/')
+			}
+			dst = appendURLPath(dst, abs)
+		case linkPath:
+			dst = appendURLPath(dst, sourcePath(frame.File))
+		case linkFile:
+			dst = appendURLPath(dst, filepath.Base(frame.File))
+		case linkLine:
+			dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		case linkFunc:
+			dst = appendURLPath(dst, frame.Function)
+		case linkRevision:
+			dst = appendURLPath(dst, buildRevision())
+		}
+	}
+	return append(dst, "\033\\"...)
+}
+
+// appendClose 追加 OSC 8 链接的结束序列
+func (l *callerLink) appendClose(dst []byte, frame runtime.Frame) []byte {
+	if l == nil || frame.File == "" {
+		return dst
+	}
+	return append(dst, "\033]8;;\033\\"...)
+}
+
+// appendURLPath 追加路径，对控制字符、空格、非 ASCII 字符和 % 进行百分号编码
+func appendURLPath(dst []byte, s string) []byte {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || c == '%' || c == '"' || c == '#' || c == '?' {
+			dst = append(dst, '%', hex[c>>4], hex[c&0xf])
+			continue
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// buildRevision 返回构建时的 VCS 版本，未知时返回 HEAD
+var buildRevision = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && s.Value != "" {
+				return s.Value
+			}
+		}
+	}
+	return "HEAD"
+})
+
+// mainModulePath 返回主模块路径，用于处理 -trimpath 构建的源文件路径
+var mainModulePath = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+})
+
+// sourceRoots 缓存源文件目录对应的仓库根目录
+var sourceRoots sync.Map
+
+// sourcePath 返回源文件相对于仓库根目录的路径（以 / 分隔）。-trimpath 构建的路径去掉主模块路径前缀；
+// 找不到仓库根目录时返回文件名
+func sourcePath(file string) string {
+	if !filepath.IsAbs(file) {
+		if mod := mainModulePath(); mod != "" && strings.HasPrefix(file, mod+"/") {
+			return file[len(mod)+1:]
+		}
+		return filepath.ToSlash(file)
+	}
+	dir := filepath.Dir(file)
+	root, ok := sourceRoots.Load(dir)
+	if !ok {
+		root, _ = sourceRoots.LoadOrStore(dir, findSourceRoot(dir))
+	}
+	if root == "" {
+		return filepath.Base(file)
+	}
+	rel, err := filepath.Rel(root.(string), file)
+	if err != nil {
+		return filepath.Base(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// findSourceRoot 从 dir 向上查找包含 .git 的目录，找不到时返回最近的 go.mod 所在目录
+func findSourceRoot(dir string) string {
+	module := ""
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		if module == "" {
+			if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+				module = dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return module
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,130 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+)
+
+func TestCallerLink_Template(t *testing.T) {
+	frame := runtime.Frame{File: "/src/my app/svc/handler.go", Line: 42, Function: "main.handle"}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"vscode", "vscode://file/src/my%20app/svc/handler.go:42"},
+		{"idea", "idea://open?file=/src/my%20app/svc/handler.go&line=42"},
+		{"x://{file}/{func}/{unknown}#L{line}", "x://handler.go/main.handle/{unknown}#L42"},
+	}
+	for _, tt := range tests {
+		got := string(newCallerLink(tt.template).appendOpen(nil, frame))
+		if got != "\033]8;;"+tt.want+"\033\\" {
+			t.Errorf("Template %q: expected %q, got: %q", tt.template, tt.want, got)
+		}
+	}
+	if newCallerLink("") != nil {
+		t.Errorf("Expected an empty template to disable links")
+	}
+}
+
+func TestCallerLink_RepositoryPath(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	link := newCallerLink("https://example.com/repo/blob/{revision}/{path}#L{line}")
+	got := string(link.appendOpen(nil, runtime.Frame{File: file, Line: 7}))
+
+	// 仓库根目录为包含 .git 的目录，源文件位于仓库根目录下
+	want := "https://example.com/repo/blob/" + buildRevision() + "/link_test.go#L7"
+	if got != "\033]8;;"+want+"\033\\" {
+		t.Errorf("Expected %q, got: %q", want, got)
+	}
+
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "svc", "api")
+	os.MkdirAll(nested, 0755)
+	os.WriteFile(filepath.Join(dir, "svc", "go.mod"), []byte("module svc\n"), 0644)
+	os.Mkdir(filepath.Join(dir, ".git"), 0755)
+	if got := sourcePath(filepath.Join(nested, "handler.go")); got != "svc/api/handler.go" {
+		t.Errorf("Expected a path relative to the repository root, got: %q", got)
+	}
+}
+
+func TestColorFormatter_CallerLink(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewColorFormatter(WithCallerLink("vscode")))
+	logger.Info("linked")
+
+	_, file, _, _ := runtime.Caller(0)
+	pattern := "\033\\]8;;vscode://file" + regexp.QuoteMeta(filepath.ToSlash(file)) + `:\d+` + "\033\\\\" +
+		`link_test\.go:\d+` + "\033\\]8;;\033\\\\ linked"
+	if !regexp.MustCompile(pattern).MatchString(buf.String()) {
+		t.Errorf("Expected an OSC 8 link around the caller, got: %q", buf.String())
+	}
+}
+
+func TestAppendCaller_Path(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, ".git"), 0755)
+	frame := runtime.Frame{File: filepath.Join(dir, "internal", "api", "user.go"), Line: 42}
+
+	if got := string(appendCaller(nil, frame, false)); got != "user.go:42" {
+		t.Errorf("Expected the file name by default, got: %q", got)
+	}
+	if got := string(appendCaller(nil, frame, true)); got != "internal/api/user.go:42" {
+		t.Errorf("Expected a path relative to the repository root, got: %q", got)
+	}
+}
+
+func TestLoggerFromEnv_CallerPath(t *testing.T) {
+	t.Setenv("YGGGO_LOG_CALLER_PATH", "true")
+	t.Setenv("YGGGO_LOG_COLOR", "false")
+
+	config := LoadConfigFromEnv()
+	if !config.CallerPath {
+		t.Fatalf("Expected YGGGO_LOG_CALLER_PATH to be loaded")
+	}
+	// 关闭颜色、不输出超链接时同样生效
+	if f, ok := createConsoleFormatter(config, &bytes.Buffer{}).(*ColorFormatter); !ok || !f.path {
+		t.Errorf("Expected the console formatter to show caller paths")
+	}
+}
+
+func TestPrettyFormatter_CallerLinkAlignment(t *testing.T) {
+	var out [2]bytes.Buffer
+	for i, link := range []string{"", "file"} {
+		logger := NewLogger(&out[i])
+		logger.SetClock(fixedClock)
+		logger.SetFormatter(NewPrettyFormatter(WithTheme(NoColorTheme), WithCallerLink(link)))
+		logger.Info("aligned")
+	}
+
+	osc := regexp.MustCompile("\033\\]8;;[^\033]*\033\\\\")
+	if osc.ReplaceAllString(out[1].String(), "") != out[0].String() {
+		t.Errorf("Expected links not to change the layout:\n%q\n%q", out[0].String(), out[1].String())
+	}
+}
+
+func TestLoggerFromEnv_CallerLink(t *testing.T) {
+	t.Setenv("YGGGO_LOG_CALLER_LINK", "vscode")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+
+	var buf bytes.Buffer
+	NewLoggerFromEnvWithOutput(&buf).Info("linked")
+	if !bytes.Contains(buf.Bytes(), []byte("\033]8;;vscode://file")) {
+		t.Errorf("Expected a caller link with colors enabled, got: %q", buf.String())
+	}
+
+	t.Setenv("YGGGO_LOG_COLOR", "true")
+	t.Setenv("NO_COLOR", "1")
+	buf.Reset()
+	logger := NewLoggerFromEnvWithOutput(&buf)
+	logger.SetFormatter(createConsoleFormatter(&LogConfig{CallerLink: "vscode", ColorMode: ColorNever}, &buf))
+	logger.Info("plain")
+	if bytes.Contains(buf.Bytes(), []byte("\033]8;;")) {
+		t.Errorf("Expected no caller link without colors, got: %q", buf.String())
+	}
+}
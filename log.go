@@ -1,10 +1,10 @@
 package ygggo_log
 
 import (
-	"fmt"
 	"io"
 	"os"
-	"strings"
+	"sync"
+	"time"
 )
 
 // LogLevel represents severity for log records in ascending order.
@@ -43,11 +43,17 @@ func (l LogLevel) String() string {
 }
 
 // Logger is a minimal, pluggable logger with level filtering and a formatter.
-// It is concurrency-safe as long as the configured output is safe for concurrent writes.
+// Built-in formatters emit each entry with a single Write, so a Logger is
+// concurrency-safe as long as the configured output is safe for concurrent
+// writes. For outputs that are not, enable SetSerialized.
 type Logger struct {
-	output    io.Writer
-	minLevel  LogLevel  // Minimum level to emit; messages below are discarded.
-	formatter Formatter // Responsible for rendering a log entry to the output.
+	output     io.Writer
+	minLevel   LogLevel         // Minimum level to emit; messages below are discarded.
+	formatter  Formatter        // Responsible for rendering a log entry to the output.
+	serialized bool             // Whether writes are serialized through mu.
+	mu         sync.Mutex       // Guards formatter writes when serialized is set.
+	clock      func() time.Time // Source of entry timestamps; nil means time.Now.
+	limits     Limits           // Size limits applied to entries before formatting.
 }
 
 // NewLogger creates a new Logger that writes to the provided output.
@@ -64,143 +70,120 @@ func NewLogger(output io.Writer) *Logger {
 	}
 }
 
-// log writes a log entry at the given level after level filtering. It accepts
-// variadic arguments and appends them to the message as key=value pairs.
-func (l *Logger) log(level LogLevel, message string, args ...any) {
-	if level < l.minLevel {
-		return
+// SetSerialized enables or disables internal serialization of writes. When
+// enabled, entries are formatted and written one at a time, which makes it safe
+// to log from several goroutines to outputs that are not concurrency-safe, such
+// as bytes.Buffer, bufio.Writer or a plain network connection. It should be
+// called before the logger is shared between goroutines.
+func (l *Logger) SetSerialized(enabled bool) {
+	l.serialized = enabled
+}
+
+// SetFormatter replaces the formatter that renders entries to the output,
+// which defaults to a TextFormatter. Pair it with the matching sink, for
+// example NewGELFFormatter with NewGELFWriter or NewSyslogFormatter with
+// NewSyslogWriter. Passing nil restores the default. It should be called
+// before the logger is shared between goroutines.
+func (l *Logger) SetFormatter(formatter Formatter) {
+	if formatter == nil {
+		formatter = NewTextFormatter()
 	}
-	fullMsg := l.buildMessage(message, args...)
-	l.formatter.Format(l.output, level, fullMsg)
+	l.formatter = formatter
 }
 
-// buildMessage joins message with formatted parameters. Supported argument forms:
-//   - string (appended directly)
+// SetClock replaces the source of entry timestamps, which defaults to
+// time.Now. Passing nil restores the default. It is mainly useful in tests
+// that need to assert exact timestamps, and should be called before the
+// logger is shared between goroutines.
+func (l *Logger) SetClock(clock func() time.Time) {
+	l.clock = clock
+}
+
+// SetLimits sets the message, field and entry size limits applied to every
+// entry before it reaches the formatter. Oversized text is cut and marked with
+// "…(truncated N bytes)"; see Limits. The zero value disables all limits,
+// which is the default. It should be called before the logger is shared
+// between goroutines.
+func (l *Logger) SetLimits(limits Limits) {
+	l.limits = limits
+}
+
+// log writes a log entry at the given level after level filtering. It accepts
+// variadic arguments and attaches them to the entry as fields. Supported argument forms:
+//   - Field values created by String, Int, Any, etc.
 //   - "key=value" strings
 //   - map[string]any
 //   - any other value, appended in order
 //
 // When ColorFormatter is in use, keys and values are colorized.
-func (l *Logger) buildMessage(message string, args ...any) string {
-	if len(args) == 0 {
-		return message
-	}
-	var params string
-	switch l.formatter.(type) {
-	case *ColorFormatter:
-		params = formatParamsColor(args...)
-	default:
-		params = formatParamsPlain(args...)
+func (l *Logger) log(level LogLevel, message string, args ...any) {
+	if level < l.minLevel {
+		return
 	}
-	if params == "" {
-		return message
+	e := acquireEntry(level, message, 2)
+	if l.clock != nil {
+		e.Time = l.clock()
 	}
-	return message + " " + params
+	e.Fields = argsToFields(e.Fields, args)
+	l.write(e)
 }
 
-// formatParamsPlain 将参数格式化为不带颜色的 key=value 串
-func formatParamsPlain(args ...any) string {
-	if len(args) == 0 {
-		return ""
+// logFields is the typed counterpart of log used by Log.
+func (l *Logger) logFields(level LogLevel, message string, fields []Field) {
+	if level < l.minLevel {
+		return
 	}
-	var b strings.Builder
-	first := true
-	for _, a := range args {
-		if a == nil {
-			continue
-		}
-		switch v := a.(type) {
-		case map[string]any:
-			for k, val := range v {
-				if !first {
-					b.WriteString(" ")
-				}
-				first = false
-				b.WriteString(k)
-				b.WriteString("=")
-				b.WriteString(fmt.Sprintf("%v", val))
-			}
-		case string:
-			if !first {
-				b.WriteString(" ")
-			}
-			first = false
-			b.WriteString(v)
-		default:
-			if !first {
-				b.WriteString(" ")
-			}
-			first = false
-			b.WriteString(fmt.Sprintf("%v", v))
-		}
+	e := acquireEntry(level, message, 2)
+	if l.clock != nil {
+		e.Time = l.clock()
 	}
-	return b.String()
-}
-
-// formatParamsColor 将参数格式化为带颜色的 key=value 串
-func formatParamsColor(args ...any) string {
-	if len(args) == 0 {
-		return ""
+	e.Fields = append(e.Fields, fields...)
+	l.write(e)
+}
+
+// write applies the size limits, hands the entry to the formatter and returns
+// it to the pool. Formatters implementing EntryFormatter receive the structured
+// entry; others receive the message with fields rendered as plain key=value text.
+// A BufferedWriter output is then told the entry's level so it can flush errors.
+func (l *Logger) write(e *Entry) {
+	l.limits.apply(e)
+	if l.serialized {
+		l.mu.Lock()
+		defer l.mu.Unlock()
 	}
-	var b strings.Builder
-	first := true
-	for _, a := range args {
-		if a == nil {
-			continue
-		}
-		switch v := a.(type) {
-		case map[string]any:
-			for k, val := range v {
-				if !first {
-					b.WriteString(" ")
-				}
-				first = false
-				b.WriteString(ColorCyan)
-				b.WriteString(k)
-				b.WriteString(ColorReset)
-				b.WriteString("=")
-				b.WriteString(colorizeValue(val))
-			}
-		case string:
-			if !first {
-				b.WriteString(" ")
-			}
-			first = false
-			parts := strings.SplitN(v, "=", 2)
-			if len(parts) == 2 {
-				b.WriteString(ColorCyan)
-				b.WriteString(parts[0])
-				b.WriteString(ColorReset)
-				b.WriteString("=")
-				b.WriteString(colorizeValue(parts[1]))
-			} else {
-				b.WriteString(v)
-			}
-		default:
-			if !first {
-				b.WriteString(" ")
-			}
-			first = false
-			b.WriteString(colorizeValue(v))
-		}
+	if ef, ok := l.formatter.(EntryFormatter); ok {
+		ef.FormatEntry(l.output, e)
+	} else {
+		formatLegacy(l.formatter, l.output, e)
 	}
-	return b.String()
+	notifyWritten(l.output, e.Level)
+	releaseEntry(e)
+}
+
+// Close releases the destinations owned by the logger's formatter, such as the
+// AsyncWriter console and rotating file created by NewLoggerFromConfig, after
+// writing out everything still queued or buffered. The logger's own output is
+// left open because it is usually os.Stdout. Call it before main returns so
+// that no queued console lines are lost.
+func (l *Logger) Close() error {
+	if l.serialized {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+	}
+	if c, ok := l.formatter.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
 }
 
-// colorizeValue 根据类型为值着色（用于彩色输出）
-func colorizeValue(v any) string {
-	switch val := v.(type) {
-	case bool:
-		return ColorYellow + fmt.Sprintf("%v", val) + ColorReset
-	case int, int8, int16, int32, int64,
-		uint, uint8, uint16, uint32, uint64:
-		return ColorGreen + fmt.Sprintf("%v", val) + ColorReset
-	case float32, float64:
-		return ColorPurple + fmt.Sprintf("%v", val) + ColorReset
-	case string:
-		return ColorWhite + val + ColorReset
-	default:
-		return ColorBlue + fmt.Sprintf("%v", val) + ColorReset
+// Log writes a message with typed fields at the given level. Unlike the
+// variadic ...any methods, fields are never boxed into interfaces, so with the
+// built-in text and JSON formatters a call allocates nothing. Logging at
+// PanicLevel panics after the entry is written, like Panic.
+func (l *Logger) Log(level LogLevel, message string, fields ...Field) {
+	l.logFields(level, message, fields)
+	if level == PanicLevel {
+		panic(message)
 	}
 }
 
@@ -235,25 +218,31 @@ var defaultLogger = NewLogger(os.Stdout)
 
 // Debug 使用默认日志记录器生成DEBUG级别的日志（支持参数）
 func Debug(message string, args ...any) {
-	defaultLogger.Debug(message, args...)
+	defaultLogger.log(DebugLevel, message, args...)
 }
 
 // Info 使用默认日志记录器生成INFO级别的日志（支持参数）
 func Info(message string, args ...any) {
-	defaultLogger.Info(message, args...)
+	defaultLogger.log(InfoLevel, message, args...)
 }
 
 // Warning 使用默认日志记录器生成WARNING级别的日志（支持参数）
 func Warning(message string, args ...any) {
-	defaultLogger.Warning(message, args...)
+	defaultLogger.log(WarningLevel, message, args...)
 }
 
 // Error 使用默认日志记录器生成ERROR级别的日志（支持参数）
 func Error(message string, args ...any) {
-	defaultLogger.Error(message, args...)
+	defaultLogger.log(ErrorLevel, message, args...)
+}
+
+// Close 关闭默认日志记录器，写完控制台队列和文件缓存中的日志，应在 main 返回前调用
+func Close() error {
+	return defaultLogger.Close()
 }
 
 // Panic 使用默认日志记录器生成Panic级别的日志并触发panic（支持参数）
 func Panic(message string, args ...any) {
-	defaultLogger.Panic(message, args...)
+	defaultLogger.log(PanicLevel, message, args...)
+	panic(message)
 }
@@ -2,7 +2,9 @@ package ygggo_log
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -114,3 +116,54 @@ func TestLogFormat(t *testing.T) {
 		t.Errorf("Expected output to end with newline, got: %s", output)
 	}
 }
+
+func TestLogger_SetSerialized(t *testing.T) {
+	// bytes.Buffer 本身不是并发安全的，需要 Logger 串行化写入
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewJsonFormatter())
+	logger.SetSerialized(true)
+
+	const goroutines, perGoroutine = 8, 200
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				logger.Info("concurrent", Int("g", g), Int("i", i))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != goroutines*perGoroutine {
+		t.Fatalf("Expected %d lines, got: %d", goroutines*perGoroutine, len(lines))
+	}
+	for _, line := range lines {
+		var entry JsonLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Corrupted JSON line %q: %v", line, err)
+		}
+	}
+}
+
+func TestLogger_SetFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewJsonFormatter())
+	logger.Info("as json")
+
+	var entry JsonLogEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil || entry.Message != "as json" {
+		t.Errorf("Expected a JSON line, got: %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.SetFormatter(nil)
+	logger.Info("as text")
+	if !strings.Contains(buf.String(), "[INFO] as text") {
+		t.Errorf("Expected nil to restore the text formatter, got: %q", buf.String())
+	}
+}
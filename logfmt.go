@@ -0,0 +1,198 @@
+package ygggo_log
+
+import (
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// LogfmtFormatter logfmt 格式化器，输出形如
+//
+//	time=2025-01-02T15:04:05.000+08:00 level=info caller=main.go:12 msg="user login" user=tom
+//
+// 的日志行，可被 Loki/Grafana、Heroku 等直接解析。包含空格、等号、引号或控制字符的值
+// 会加上双引号并转义；位置参数没有键名，依次命名为 arg0、arg1……；
+// 与 time、level、caller、msg 同名的参数加上 labels. 前缀，避免出现重复的键
+type LogfmtFormatter struct {
+	time   timeEncoder
+	static []byte // 预先编码的常量字段
+}
+
+// NewLogfmtFormatter 创建 logfmt 格式化器，默认时间布局为带毫秒的 RFC3339
+func NewLogfmtFormatter(opts ...FormatterOption) *LogfmtFormatter {
+	o := newFormatterOptions(opts)
+	return &LogfmtFormatter{
+		time:   newTimeEncoder(o, "2006-01-02T15:04:05.000Z07:00"),
+		static: appendLogfmtFields(nil, o.staticFields),
+	}
+}
+
+// Format 格式化为 logfmt 格式
+func (f *LogfmtFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为 logfmt 格式
+func (f *LogfmtFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	buf.b = append(buf.b, "time="...)
+	start := len(buf.b)
+	buf.b = f.time.append(buf.b, e.Time)
+	buf.b = quoteLogfmtTail(buf.b, start)
+
+	buf.b = append(buf.b, " level="...)
+	buf.b = appendLowerASCII(buf.b, e.Level.String())
+
+	file, line := e.Caller()
+	buf.b = append(buf.b, " caller="...)
+	start = len(buf.b)
+	buf.b = append(buf.b, filepath.Base(file)...)
+	buf.b = append(buf.b, ':')
+	buf.b = strconv.AppendInt(buf.b, int64(line), 10)
+	buf.b = quoteLogfmtTail(buf.b, start)
+
+	buf.b = append(buf.b, " msg="...)
+	buf.b = appendLogfmtString(buf.b, e.Message)
+	buf.b = append(buf.b, f.static...)
+	buf.b = appendLogfmtFields(buf.b, e.Fields)
+	buf.b = append(buf.b, '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// logfmtReservedKeys logfmt 格式固定输出的键
+var logfmtReservedKeys = []string{"time", "level", "caller", "msg"}
+
+// appendLogfmtFields 以 key=value 的形式追加参数，每个参数前都带空格
+func appendLogfmtFields(dst []byte, fields []Field) []byte {
+	positional := 0
+	for _, field := range fields {
+		dst = append(dst, ' ')
+		if slices.Contains(logfmtReservedKeys, field.Key) {
+			dst = append(dst, "labels."...)
+		}
+		dst = appendFieldKey(dst, field, &positional, appendLogfmtKey)
+		dst = append(dst, '=')
+		dst = appendLogfmtValue(dst, field)
+	}
+	return dst
+}
+
+// appendLogfmtValue 追加参数值，字符串类的值按需加引号
+func appendLogfmtValue(dst []byte, f Field) []byte {
+	switch f.Type {
+	case StringType:
+		return appendLogfmtString(dst, f.str)
+	case IntType, UintType, FloatType, BoolType:
+		return appendFieldValue(dst, f)
+	default:
+		start := len(dst)
+		dst = appendFieldValue(dst, f)
+		return quoteLogfmtTail(dst, start)
+	}
+}
+
+// appendLogfmtKey 追加键名，将空白、等号、引号和控制字符替换为下划线
+func appendLogfmtKey(dst []byte, key string) []byte {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// appendLogfmtString 追加字符串值，需要时加双引号并转义
+func appendLogfmtString(dst []byte, s string) []byte {
+	if !needsLogfmtQuote(s) {
+		return append(dst, s...)
+	}
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, `\ufffd`...)
+			} else {
+				dst = append(dst, s[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			if c < ' ' || c == 0x7f {
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			} else {
+				dst = append(dst, c)
+			}
+		}
+		i++
+	}
+	return append(dst, '"')
+}
+
+// needsLogfmtQuote 判断值是否需要加引号：空串、含空白、等号、引号、反斜杠、控制字符或非法 UTF-8
+func needsLogfmtQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				return true
+			}
+			i += size
+			continue
+		}
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return true
+		}
+		i++
+	}
+	return false
+}
+
+// quoteLogfmtTail 检查 dst[start:] 是否需要加引号，需要时原地改写
+func quoteLogfmtTail(dst []byte, start int) []byte {
+	tail := dst[start:]
+	quote := len(tail) == 0 || !utf8.Valid(tail)
+	for _, c := range tail {
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			quote = true
+			break
+		}
+	}
+	if !quote {
+		return dst
+	}
+	s := string(tail)
+	return appendLogfmtString(dst[:start], s)
+}
+
+// appendLowerASCII 追加 ASCII 字符串的小写形式
+func appendLowerASCII(dst []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
@@ -0,0 +1,132 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtFormatter_Line(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewLogfmtFormatter(WithUTC()))
+
+	logger.Info("user login", "user=tom", map[string]any{"n": 3}, 2.5)
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "time=2025-06-07T00:09:10.123Z level=info caller=logfmt_test.go:") {
+		t.Errorf("Unexpected prefix: %q", out)
+	}
+	if !strings.HasSuffix(out, ` msg="user login" user=tom n=3 arg0=2.5`+"\n") {
+		t.Errorf("Unexpected suffix: %q", out)
+	}
+}
+
+func TestLogfmtFormatter_Quoting(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"", `""`},
+		{"has space", `"has space"`},
+		{"a=b", `"a=b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"line1\nline2", `"line1\nline2"`},
+		{`back\slash`, `"back\\slash"`},
+		{"bell\x07", `"bell\u0007"`},
+		{"中文", "中文"},
+	}
+
+	for _, tc := range testCases {
+		if got := string(appendLogfmtString(nil, tc.value)); got != tc.expected {
+			t.Errorf("Expected %s for %q, got: %s", tc.expected, tc.value, got)
+		}
+	}
+}
+
+func TestLogfmtFormatter_KeysAndTypedValues(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewLogfmtFormatter()
+	f.FormatEntry(&buf, &Entry{
+		Time:    time.Now(),
+		Level:   WarningLevel,
+		Message: "typed",
+		Fields: []Field{
+			String("bad key=", "v"),
+			Err(errors.New("disk full")),
+			Duration("took", 1500*time.Millisecond),
+			Bool("ok", false),
+		},
+	})
+
+	out := buf.String()
+	for _, expect := range []string{" level=warning ", " bad_key_=v", ` error="disk full"`, " took=1.5s", " ok=false\n"} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("Expected %q in output: %q", expect, out)
+		}
+	}
+}
+
+func TestLogfmtFormatter_FieldsDoNotCollide(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewLogfmtFormatter(WithStaticFields(String("level", "static")))
+	f.FormatEntry(&buf, &Entry{
+		Time:    time.Now(),
+		Level:   InfoLevel,
+		Message: "hello",
+		Fields:  []Field{String("msg", "spoof"), String("time", "t"), String("caller", "c"), String("user", "tom")},
+	})
+
+	out := buf.String()
+	for _, key := range []string{" time=", " level=", " caller=", " msg="} {
+		if strings.Count(" "+out, key) != 1 {
+			t.Errorf("Expected %q exactly once in output: %q", key, out)
+		}
+	}
+	if !strings.HasSuffix(out, ` msg=hello labels.level=static labels.msg=spoof labels.time=t labels.caller=c user=tom`+"\n") {
+		t.Errorf("Expected colliding fields under labels., got: %q", out)
+	}
+}
+
+func TestLoadConfigFromEnv_LogfmtFormat(t *testing.T) {
+	os.Setenv("YGGGO_LOG_FORMAT", "logfmt")
+	defer os.Unsetenv("YGGGO_LOG_FORMAT")
+
+	config := LoadConfigFromEnv()
+	if config.Format != LogfmtFormat || config.Format.String() != "logfmt" {
+		t.Fatalf("Expected LogfmtFormat, got: %v", config.Format)
+	}
+
+	var buf bytes.Buffer
+	logger := NewLoggerFromEnvWithOutput(&buf)
+	logger.Info("from env")
+	if !strings.Contains(buf.String(), `level=info`) || !strings.Contains(buf.String(), `msg="from env"`) {
+		t.Errorf("Expected logfmt output, got: %q", buf.String())
+	}
+}
+
+func TestLoggerFromConfig_LogfmtFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "logfmt.log")
+	logger := NewLoggerFromConfig(&LogConfig{
+		Level:      InfoLevel,
+		OutputFile: logFile,
+		Format:     LogfmtFormat,
+		FileSize:   1024 * 1024,
+		FileNum:    2,
+	})
+	logger.Error("to file", "code=E1")
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), `level=error`) || !strings.Contains(string(content), "code=E1") {
+		t.Errorf("Expected logfmt file output, got: %q", content)
+	}
+}
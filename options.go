@@ -0,0 +1,110 @@
+package ygggo_log
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// FormatterOption 配置内置格式化器的可选项，传给 NewTextFormatter 等构造函数。
+// 每个格式化器只读取与自身相关的配置，其余配置会被忽略。
+type FormatterOption func(*formatterOptions)
+
+// formatterOptions 内置格式化器共用的配置
+type formatterOptions struct {
+	timeLayout    string
+	timePrecision TimePrecision
+	timeLocation  *time.Location
+	serviceName   string
+	staticFields  []Field
+
+	timeKey        string
+	levelKey       string
+	messageKey     string
+	lowercaseLevel bool
+	fieldsMode     jsonFieldsMode
+	fieldsKey      string
+
+	projectID        string
+	metricNamespace  string
+	metricDimensions []string
+
+	facility    SyslogFacility
+	facilitySet bool
+
+	columns []string
+	header  bool
+
+	theme      *Theme
+	callerLink string
+	callerPath bool
+	rawText    bool
+}
+
+// newFormatterOptions 应用可选项
+func newFormatterOptions(opts []FormatterOption) formatterOptions {
+	var o formatterOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithServiceName 设置服务名，供 ECS 等需要标识服务的格式使用；默认取可执行文件名
+func WithServiceName(name string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.serviceName = name
+	}
+}
+
+// WithStaticFields 设置附加到每条日志的常量字段，如 service、env、version。
+// JSON、logfmt、ECS、云平台和 GELF 格式在内置字段之后、日志参数之前输出这些字段。
+// 与格式内置字段同名的常量字段不会重复输出：ECS 和云平台格式把 service、version 等
+// 映射到各自的服务字段，其余同名字段在 ECS 中加 labels. 前缀，在其他 JSON 格式中忽略
+func WithStaticFields(fields ...Field) FormatterOption {
+	return func(o *formatterOptions) {
+		o.staticFields = append(o.staticFields, fields...)
+	}
+}
+
+// service 返回配置的服务名，未配置时使用可执行文件名
+func (o formatterOptions) service() string {
+	if o.serviceName != "" {
+		return o.serviceName
+	}
+	return filepath.Base(os.Args[0])
+}
+
+// takeStaticField 从常量字段中取出键名为 keys 之一的最后一个字段的文本值，
+// 返回该值和去掉这些键后的字段副本；没有时返回空字符串
+func takeStaticField(fields []Field, keys ...string) (string, []Field) {
+	var value string
+	found := false
+	for _, f := range fields {
+		if slices.Contains(keys, f.Key) {
+			value, found = f.String(), true
+		}
+	}
+	if !found {
+		return "", fields
+	}
+	return value, slices.DeleteFunc(slices.Clone(fields), func(f Field) bool {
+		return slices.Contains(keys, f.Key)
+	})
+}
+
+// dropStaticFields 返回去掉与内置字段同名（reserved 返回 true）的常量字段后的副本
+func dropStaticFields(fields []Field, reserved func(key string) bool) []Field {
+	return slices.DeleteFunc(slices.Clone(fields), func(f Field) bool {
+		return reserved(f.Key)
+	})
+}
+
+// hostname 返回主机名，获取失败时返回空字符串
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
@@ -0,0 +1,183 @@
+package ygggo_log
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// OTLPScopeName OTLPFormatter 输出的 instrumentation scope 名称
+const OTLPScopeName = "github.com/yggai/ygggo_log"
+
+// OTLPFormatter 按 OpenTelemetry 日志数据模型输出 OTLP/JSON，每条日志为一行完整的
+// ExportLogsServiceRequest，可被 OpenTelemetry Collector 的 otlpjsonfile 接收器读取，
+// 也可交给 OTLPWriter 批量发送到 OTLP/HTTP 端点：
+//
+//	{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},
+//	 "scopeLogs":[{"scope":{"name":"github.com/yggai/ygggo_log"},"logRecords":[{"timeUnixNano":"...",
+//	 "severityNumber":9,"severityText":"INFO","body":{"stringValue":"..."},"attributes":[...],
+//	 "traceId":"...","spanId":"..."}]}]}]}
+//
+// 资源属性包括 service.name、host.name 和常量字段；TraceID/SpanID 参数映射到 traceId/spanId，
+// 第一个键为 error 或无键名的错误映射到 exception.type 和 exception.message，
+// 其余参数作为记录属性，位置参数依次命名为 arg0、arg1……
+type OTLPFormatter struct {
+	prefix []byte // 预先编码的 resource 和 scope，直到 logRecords 的 [
+}
+
+// NewOTLPFormatter 创建 OTLP/JSON 格式化器，服务名通过 WithServiceName 设置，默认取可执行文件名；
+// 时间固定为 Unix 纳秒，时间配置不生效
+func NewOTLPFormatter(opts ...FormatterOption) *OTLPFormatter {
+	o := newFormatterOptions(opts)
+	attrs := []Field{String("service.name", o.service())}
+	if host := hostname(); host != "" {
+		attrs = append(attrs, String("host.name", host))
+	}
+	attrs = append(attrs, o.staticFields...)
+
+	prefix := []byte(`{"resourceLogs":[{"resource":{"attributes":[`)
+	prefix = appendOTLPAttributes(prefix, attrs, -1, true)
+	prefix = append(prefix, `]},"scopeLogs":[{"scope":{"name":"`+OTLPScopeName+`"},"logRecords":[`...)
+	return &OTLPFormatter{prefix: prefix}
+}
+
+// Format 格式化为 OTLP/JSON
+func (f *OTLPFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为 OTLP/JSON
+func (f *OTLPFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	buf.b = append(buf.b, f.prefix...)
+
+	buf.b = append(buf.b, `{"timeUnixNano":"`...)
+	buf.b = strconv.AppendInt(buf.b, e.Time.UnixNano(), 10)
+	buf.b = append(buf.b, `","observedTimeUnixNano":"`...)
+	buf.b = strconv.AppendInt(buf.b, e.Time.UnixNano(), 10)
+	buf.b = append(buf.b, `","severityNumber":`...)
+	buf.b = strconv.AppendInt(buf.b, int64(otlpSeverity(e.Level)), 10)
+	buf.b = append(buf.b, `,"severityText":"`...)
+	buf.b = append(buf.b, e.Level.String()...)
+	buf.b = append(buf.b, `","body":{"stringValue":`...)
+	buf.b = appendJSONString(buf.b, e.Message)
+	buf.b = append(buf.b, `},"attributes":[`...)
+
+	first := true
+	frame := e.CallerFrame()
+	if frame.File != "" {
+		buf.b = append(buf.b, `{"key":"code.filepath","value":{"stringValue":`...)
+		buf.b = appendJSONString(buf.b, frame.File)
+		buf.b = append(buf.b, `}},{"key":"code.lineno","value":{"intValue":"`...)
+		buf.b = strconv.AppendInt(buf.b, int64(frame.Line), 10)
+		buf.b = append(buf.b, `"}},{"key":"code.function","value":{"stringValue":`...)
+		buf.b = appendJSONString(buf.b, frame.Function)
+		buf.b = append(buf.b, `}}`...)
+		first = false
+	}
+
+	errIndex := errorFieldIndex(e.Fields)
+	if errIndex >= 0 {
+		if !first {
+			buf.b = append(buf.b, ',')
+		}
+		buf.b = appendOTLPException(buf.b, e.Fields[errIndex])
+		first = false
+	}
+	buf.b = appendOTLPAttributes(buf.b, e.Fields, errIndex, first)
+	buf.b = append(buf.b, ']')
+
+	if id, ok := fieldString(e.Fields, TraceIDKey); ok {
+		buf.b = append(buf.b, `,"traceId":`...)
+		buf.b = appendJSONString(buf.b, id)
+	}
+	if id, ok := fieldString(e.Fields, SpanIDKey); ok {
+		buf.b = append(buf.b, `,"spanId":`...)
+		buf.b = appendJSONString(buf.b, id)
+	}
+	buf.b = append(buf.b, "}]}]}]}\n"...)
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendOTLPAttributes 以 KeyValue 列表的形式追加参数，跳过下标为 skip 的参数和 trace_id/span_id；
+// first 为 false 时第一个属性前带逗号
+func appendOTLPAttributes(dst []byte, fields []Field, skip int, first bool) []byte {
+	positional := 0
+	for i, field := range fields {
+		if i == skip || field.Key == TraceIDKey || field.Key == SpanIDKey {
+			continue
+		}
+		if !first {
+			dst = append(dst, ',')
+		}
+		first = false
+		dst = append(dst, `{"key":"`...)
+		dst = appendFieldKey(dst, field, &positional, appendJSONEscaped)
+		dst = append(dst, `","value":`...)
+		dst = appendOTLPValue(dst, field)
+		dst = append(dst, '}')
+	}
+	return dst
+}
+
+// appendOTLPException 追加错误对应的 exception.type 和 exception.message 属性
+func appendOTLPException(dst []byte, f Field) []byte {
+	dst = append(dst, `{"key":"exception.type","value":{"stringValue":`...)
+	dst = appendJSONString(dst, fmt.Sprintf("%T", f.iface))
+	dst = append(dst, `}},{"key":"exception.message","value":{"stringValue":"`...)
+	dst = appendFieldValueFunc(dst, f, appendJSONEscaped)
+	return append(dst, `"}}`...)
+}
+
+// appendOTLPValue 以 AnyValue 的形式追加参数值；64 位整数按 OTLP/JSON 约定编码为字符串
+func appendOTLPValue(dst []byte, f Field) []byte {
+	switch f.Type {
+	case StringType:
+		dst = append(dst, `{"stringValue":`...)
+		dst = appendJSONString(dst, f.str)
+		return append(dst, '}')
+	case IntType:
+		dst = append(dst, `{"intValue":"`...)
+		dst = strconv.AppendInt(dst, f.num, 10)
+		return append(dst, `"}`...)
+	case UintType:
+		if uint64(f.num) <= math.MaxInt64 {
+			dst = append(dst, `{"intValue":"`...)
+			dst = strconv.AppendUint(dst, uint64(f.num), 10)
+			return append(dst, `"}`...)
+		}
+	case FloatType:
+		if v := f.float(); !math.IsNaN(v) && !math.IsInf(v, 0) {
+			dst = append(dst, `{"doubleValue":`...)
+			dst = appendFieldValue(dst, f)
+			return append(dst, '}')
+		}
+	case BoolType:
+		dst = append(dst, `{"boolValue":`...)
+		dst = appendFieldValue(dst, f)
+		return append(dst, '}')
+	}
+	dst = append(dst, `{"stringValue":"`...)
+	dst = appendFieldValueFunc(dst, f, appendJSONEscaped)
+	return append(dst, `"}`...)
+}
+
+// otlpSeverity 将日志级别映射为 OpenTelemetry 的 SeverityNumber
+func otlpSeverity(level LogLevel) int {
+	switch level {
+	case DebugLevel:
+		return 5 // DEBUG
+	case InfoLevel:
+		return 9 // INFO
+	case WarningLevel:
+		return 13 // WARN
+	case ErrorLevel:
+		return 17 // ERROR
+	case PanicLevel:
+		return 21 // FATAL
+	default:
+		return 0 // UNSPECIFIED
+	}
+}
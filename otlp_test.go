@@ -0,0 +1,104 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// otlpAttrs 将 KeyValue 列表转换为 key -> AnyValue 的 map
+func otlpAttrs(list []any) map[string]map[string]any {
+	attrs := make(map[string]map[string]any)
+	for _, item := range list {
+		kv := item.(map[string]any)
+		attrs[kv["key"].(string)] = kv["value"].(map[string]any)
+	}
+	return attrs
+}
+
+func TestOTLPFormatter_Record(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewOTLPFormatter(WithServiceName("api"), WithStaticFields(String("deployment.environment", "prod"))))
+
+	logger.Error("charge failed", errors.New("declined"), TraceID("4bf92f3577b34da6a3ce929d0e0e4736"), SpanID("00f067aa0ba902b7"),
+		String("user", "tom"), Int("amount", 42), Float64("ratio", 0.5), Bool("retry", true), "extra")
+
+	var req struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []any `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				Scope struct {
+					Name string `json:"name"`
+				} `json:"scope"`
+				LogRecords []map[string]any `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &req); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if !strings.HasSuffix(buf.String(), "}\n") || strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("Expected exactly one JSON line, got: %q", buf.String())
+	}
+
+	resource := otlpAttrs(req.ResourceLogs[0].Resource.Attributes)
+	if resource["service.name"]["stringValue"] != "api" || resource["deployment.environment"]["stringValue"] != "prod" {
+		t.Errorf("Unexpected resource attributes: %v", resource)
+	}
+	scope := req.ResourceLogs[0].ScopeLogs[0]
+	if scope.Scope.Name != OTLPScopeName {
+		t.Errorf("Unexpected scope: %v", scope.Scope.Name)
+	}
+
+	rec := scope.LogRecords[0]
+	if rec["timeUnixNano"] != strconv.FormatInt(fixedClock().UnixNano(), 10) {
+		t.Errorf("Unexpected timeUnixNano: %v", rec["timeUnixNano"])
+	}
+	if rec["severityNumber"] != float64(17) || rec["severityText"] != "ERROR" {
+		t.Errorf("Unexpected severity: %v %v", rec["severityNumber"], rec["severityText"])
+	}
+	if rec["body"].(map[string]any)["stringValue"] != "charge failed" {
+		t.Errorf("Unexpected body: %v", rec["body"])
+	}
+	if rec["traceId"] != "4bf92f3577b34da6a3ce929d0e0e4736" || rec["spanId"] != "00f067aa0ba902b7" {
+		t.Errorf("Unexpected trace context: %v %v", rec["traceId"], rec["spanId"])
+	}
+
+	attrs := otlpAttrs(rec["attributes"].([]any))
+	expected := map[string][2]any{
+		"exception.message": {"stringValue", "declined"},
+		"exception.type":    {"stringValue", "*errors.errorString"},
+		"user":              {"stringValue", "tom"},
+		"amount":            {"intValue", "42"},
+		"ratio":             {"doubleValue", 0.5},
+		"retry":             {"boolValue", true},
+		"arg0":              {"stringValue", "extra"},
+	}
+	for key, want := range expected {
+		if attrs[key][want[0].(string)] != want[1] {
+			t.Errorf("Expected %s=%v, got: %v", key, want, attrs[key])
+		}
+	}
+	if !strings.HasSuffix(attrs["code.filepath"]["stringValue"].(string), "otlp_test.go") {
+		t.Errorf("Unexpected code.filepath: %v", attrs["code.filepath"])
+	}
+	if _, ok := attrs[TraceIDKey]; ok {
+		t.Error("Expected trace_id not to be repeated as an attribute")
+	}
+}
+
+func TestOTLPSeverity(t *testing.T) {
+	expected := map[LogLevel]int{DebugLevel: 5, InfoLevel: 9, WarningLevel: 13, ErrorLevel: 17, PanicLevel: 21}
+	for level, number := range expected {
+		if got := otlpSeverity(level); got != number {
+			t.Errorf("Expected %v to map to %d, got: %d", level, number, got)
+		}
+	}
+}
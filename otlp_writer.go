@@ -0,0 +1,440 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultOTLPBatchSize is the default number of records per export request.
+	DefaultOTLPBatchSize = 512
+	// DefaultOTLPFlushInterval is the default delay before a partial batch is exported.
+	DefaultOTLPFlushInterval = time.Second
+	// DefaultOTLPMaxRetries is the default number of retries of a failed export.
+	DefaultOTLPMaxRetries = 3
+	// DefaultOTLPRetryBackoff is the default delay before the first retry.
+	DefaultOTLPRetryBackoff = 500 * time.Millisecond
+	// DefaultOTLPShutdownTimeout is the default time Close waits for the final exports.
+	DefaultOTLPShutdownTimeout = 5 * time.Second
+
+	// otlpMaxBackoff caps the doubling delay between retries.
+	otlpMaxBackoff = 30 * time.Second
+	// otlpQueueBatches is how many full batches may wait for export before
+	// further records are dropped.
+	otlpQueueBatches = 16
+)
+
+// otlpRequest is the part of an ExportLogsServiceRequest that OTLPWriter
+// needs to merge records; resources, scopes and records stay raw JSON.
+type otlpRequest struct {
+	ResourceLogs []struct {
+		Resource  json.RawMessage `json:"resource"`
+		ScopeLogs []struct {
+			Scope      json.RawMessage   `json:"scope"`
+			LogRecords []json.RawMessage `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+// otlpBatchKey identifies records that share a resource and scope.
+type otlpBatchKey struct {
+	resource string
+	scope    string
+}
+
+// OTLPWriter exports OTLP/JSON log records, as produced by OTLPFormatter, to an
+// OTLP/HTTP endpoint such as an OpenTelemetry Collector. Each Write must hold
+// one ExportLogsServiceRequest. Write only queues the records; a background
+// goroutine POSTs them when a batch is full, when the flush interval elapses,
+// and on Flush and Close. Records that share a resource and scope are merged
+// into one entry.
+//
+// Failed exports are retried with exponential backoff on network errors and
+// on 429, 502, 503 and 504 responses (see SetRetry). While exports fail, up to
+// 16 batches are queued; later records are dropped. Export errors and dropped
+// records are reported by the next Flush or Close. Close gives up on exports
+// and retries still running after the shutdown timeout (see
+// SetShutdownTimeout) and reports the records it dropped. Writes after Close
+// return ErrWriterClosed. OTLPWriter is safe for concurrent use.
+type OTLPWriter struct {
+	endpoint   string
+	client     *http.Client
+	headers    http.Header
+	batchSize  int
+	interval   time.Duration
+	maxRetries int
+	backoff    time.Duration
+	shutdown   time.Duration // how long Close waits for the final exports
+
+	order   []otlpBatchKey                     // resource/scope pairs in arrival order
+	pending map[otlpBatchKey][]json.RawMessage // records waiting to be exported
+	count   int                                // number of pending records
+	dropped int                                // records dropped since the last Flush
+	timer   *time.Timer                        // pending interval flush
+	err     error                              // first export error since the last Flush
+	closed  bool                               // set by Close
+	mutex   sync.Mutex                         // guards the fields above; never held during an export
+
+	ctx     context.Context    // aborts exports and retries once canceled
+	cancel  context.CancelFunc // called when the shutdown timeout expires
+	full    chan struct{}      // a full batch is waiting
+	tick    chan struct{}      // the flush interval elapsed
+	flushes chan chan struct{} // Flush requests, closed once everything before them is exported
+	quit    chan struct{}      // closed by Close
+	done    chan struct{}      // closed when the export goroutine exits
+}
+
+// NewOTLPWriter creates an OTLPWriter for the given OTLP/HTTP endpoint and
+// starts its export goroutine; call Close to stop it. An endpoint without a
+// path, such as http://localhost:4318, gets /v1/logs.
+func NewOTLPWriter(endpoint string) (*OTLPWriter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("otlp: unsupported endpoint %q", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/logs"
+	}
+	w := &OTLPWriter{
+		endpoint:   u.String(),
+		client:     &http.Client{Timeout: 10 * time.Second},
+		headers:    http.Header{},
+		batchSize:  DefaultOTLPBatchSize,
+		interval:   DefaultOTLPFlushInterval,
+		maxRetries: DefaultOTLPMaxRetries,
+		backoff:    DefaultOTLPRetryBackoff,
+		shutdown:   DefaultOTLPShutdownTimeout,
+		pending:    make(map[otlpBatchKey][]json.RawMessage),
+		full:       make(chan struct{}, 1),
+		tick:       make(chan struct{}, 1),
+		flushes:    make(chan chan struct{}),
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	go w.loop()
+	return w, nil
+}
+
+// SetHeader sets an HTTP header sent with every export, e.g. an API key.
+func (w *OTLPWriter) SetHeader(key, value string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.headers.Set(key, value)
+}
+
+// SetHTTPClient replaces the HTTP client used for exports.
+func (w *OTLPWriter) SetHTTPClient(client *http.Client) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if client != nil {
+		w.client = client
+	}
+}
+
+// SetBatchSize sets the number of records that triggers an export.
+// Values below 1 reset it to DefaultOTLPBatchSize.
+func (w *OTLPWriter) SetBatchSize(size int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if size < 1 {
+		size = DefaultOTLPBatchSize
+	}
+	w.batchSize = size
+}
+
+// SetFlushInterval sets how long a partial batch may wait before it is exported.
+// Zero or negative values disable interval flushes.
+func (w *OTLPWriter) SetFlushInterval(interval time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.interval = interval
+}
+
+// SetRetry sets how many times a failed export is retried and the delay
+// before the first retry, which doubles for each further retry up to 30s.
+// maxRetries 0 disables retries; a backoff of zero or less resets it to
+// DefaultOTLPRetryBackoff.
+func (w *OTLPWriter) SetRetry(maxRetries int, backoff time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if backoff <= 0 {
+		backoff = DefaultOTLPRetryBackoff
+	}
+	w.maxRetries = max(maxRetries, 0)
+	w.backoff = backoff
+}
+
+// SetShutdownTimeout sets how long Close waits for pending records to be
+// exported, including retries. Records not exported by then are dropped and
+// reported by Close. Zero or negative values reset it to
+// DefaultOTLPShutdownTimeout.
+func (w *OTLPWriter) SetShutdownTimeout(timeout time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if timeout <= 0 {
+		timeout = DefaultOTLPShutdownTimeout
+	}
+	w.shutdown = timeout
+}
+
+// Write queues the records of one ExportLogsServiceRequest. It never waits
+// for an export.
+func (w *OTLPWriter) Write(p []byte) (int, error) {
+	var req otlpRequest
+	if err := json.Unmarshal(p, &req); err != nil {
+		return 0, fmt.Errorf("otlp: invalid request: %w", err)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			if len(sl.LogRecords) == 0 {
+				continue
+			}
+			if w.count+len(sl.LogRecords) > otlpQueueBatches*w.batchSize {
+				w.dropped += len(sl.LogRecords)
+				continue
+			}
+			key := otlpBatchKey{resource: string(rl.Resource), scope: string(sl.Scope)}
+			if _, ok := w.pending[key]; !ok {
+				w.order = append(w.order, key)
+			}
+			w.pending[key] = append(w.pending[key], sl.LogRecords...)
+			w.count += len(sl.LogRecords)
+		}
+	}
+
+	if w.count >= w.batchSize {
+		signal(w.full)
+	}
+	// Records left over after the full batches are exported wait for the interval.
+	if w.count > 0 && w.timer == nil && w.interval > 0 {
+		w.timer = time.AfterFunc(w.interval, func() { signal(w.tick) })
+	}
+	return len(p), nil
+}
+
+// Flush waits until every record written before the call has been exported or
+// has failed all its retries, and returns the first export error since the
+// last Flush, including records dropped because the queue was full.
+func (w *OTLPWriter) Flush() error {
+	done := make(chan struct{})
+	select {
+	case w.flushes <- done:
+		<-done
+	case <-w.done:
+	}
+	return w.takeErr()
+}
+
+// Close exports all pending records, stops the export goroutine and returns
+// the first export error not yet reported. Exports still running after the
+// shutdown timeout are aborted and their records dropped. Later writes return
+// ErrWriterClosed; calling Close again is safe.
+func (w *OTLPWriter) Close() error {
+	w.mutex.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.quit)
+		timer := time.AfterFunc(w.shutdown, w.cancel)
+		defer timer.Stop()
+	}
+	w.mutex.Unlock()
+	<-w.done
+	w.cancel()
+	return w.takeErr()
+}
+
+// loop is the export goroutine.
+func (w *OTLPWriter) loop() {
+	defer close(w.done)
+	for {
+		select {
+		case <-w.full:
+			w.exportPending(false)
+		case <-w.tick:
+			w.mutex.Lock()
+			w.timer = nil
+			w.mutex.Unlock()
+			w.exportPending(true)
+		case done := <-w.flushes:
+			w.exportPending(true)
+			close(done)
+		case <-w.quit:
+			w.exportPending(true)
+			return
+		}
+	}
+}
+
+// exportPending exports the pending records in batches: all of them, or only
+// full batches when all is false.
+func (w *OTLPWriter) exportPending(all bool) {
+	for {
+		w.mutex.Lock()
+		if w.count == 0 || (!all && w.count < w.batchSize) {
+			w.mutex.Unlock()
+			return
+		}
+		n := w.count
+		body := w.takeBatchLocked()
+		n -= w.count
+		client, headers := w.client, w.headers.Clone()
+		maxRetries, backoff := w.maxRetries, w.backoff
+		w.mutex.Unlock()
+
+		err := w.export(client, headers, body, maxRetries, backoff)
+		if err == nil {
+			continue
+		}
+		w.mutex.Lock()
+		if w.ctx.Err() != nil {
+			// Close timed out: drop this batch and everything still pending.
+			n += w.count
+			clear(w.pending)
+			w.order, w.count = w.order[:0], 0
+			w.err = errors.Join(w.err, fmt.Errorf("otlp: dropped %d records because Close timed out after %v", n, w.shutdown))
+			w.mutex.Unlock()
+			return
+		}
+		if w.err == nil {
+			w.err = err
+		}
+		w.mutex.Unlock()
+	}
+}
+
+// takeBatchLocked removes up to batchSize pending records, oldest first, and
+// returns them as one request body. The caller must hold the mutex.
+func (w *OTLPWriter) takeBatchLocked() []byte {
+	var body bytes.Buffer
+	body.WriteString(`{"resourceLogs":[`)
+	n, keys := 0, 0
+	for i, key := range w.order {
+		if n == w.batchSize {
+			break
+		}
+		records := w.pending[key]
+		take := min(len(records), w.batchSize-n)
+		if i > 0 {
+			body.WriteByte(',')
+		}
+		body.WriteString(`{"resource":`)
+		writeRawOrEmpty(&body, key.resource)
+		body.WriteString(`,"scopeLogs":[{"scope":`)
+		writeRawOrEmpty(&body, key.scope)
+		body.WriteString(`,"logRecords":[`)
+		for j, rec := range records[:take] {
+			if j > 0 {
+				body.WriteByte(',')
+			}
+			body.Write(rec)
+		}
+		body.WriteString(`]}]}`)
+
+		n += take
+		if take == len(records) {
+			delete(w.pending, key)
+			keys++
+		} else {
+			w.pending[key] = records[take:]
+		}
+	}
+	body.WriteString(`]}`)
+
+	w.order = slices.Delete(w.order, 0, keys)
+	w.count -= n
+	if w.count == 0 && w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	return body.Bytes()
+}
+
+// export POSTs one request body, retrying retryable failures with a doubling backoff.
+func (w *OTLPWriter) export(client *http.Client, headers http.Header, body []byte, maxRetries int, backoff time.Duration) error {
+	for attempt := 0; ; attempt++ {
+		retryable, err := w.post(client, headers, body)
+		if err == nil || !retryable || attempt >= maxRetries {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-w.ctx.Done():
+			return err
+		}
+		backoff = min(backoff*2, otlpMaxBackoff)
+	}
+}
+
+// post sends one export request and reports whether a failure may be retried.
+func (w *OTLPWriter) post(client *http.Client, headers http.Header, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, w.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header = headers
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		return true, fmt.Errorf("otlp: export failed: %s", resp.Status)
+	default:
+		return false, fmt.Errorf("otlp: export failed: %s", resp.Status)
+	}
+}
+
+// takeErr returns and clears the recorded export error and dropped record count.
+func (w *OTLPWriter) takeErr() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	err := w.err
+	if w.dropped > 0 {
+		err = errors.Join(err, fmt.Errorf("otlp: dropped %d records because the export queue was full", w.dropped))
+	}
+	w.err, w.dropped = nil, 0
+	return err
+}
+
+// signal wakes the export goroutine without blocking when it is already signaled.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// writeRawOrEmpty writes raw JSON, or an empty object when it is missing.
+func writeRawOrEmpty(buf *bytes.Buffer, raw string) {
+	if raw == "" || raw == "null" {
+		buf.WriteString("{}")
+		return
+	}
+	buf.WriteString(raw)
+}
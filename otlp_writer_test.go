@@ -0,0 +1,286 @@
+package ygggo_log
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// otlpCollector 模拟 OTLP/HTTP 接收端，记录收到的请求
+type otlpCollector struct {
+	mu       sync.Mutex
+	requests []otlpRequest
+	headers  []http.Header
+	paths    []string
+	status   int           // 非 0 时所有请求返回该状态码
+	failures int           // 前 failures 个请求返回 503
+	block    chan struct{} // 非 nil 时请求等待其关闭后才返回
+}
+
+func (c *otlpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.block != nil {
+		<-c.block
+	}
+	body, _ := io.ReadAll(r.Body)
+	var req otlpRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, r.Header.Clone())
+	c.paths = append(c.paths, r.URL.Path)
+	status := c.status
+	if c.failures > 0 {
+		c.failures--
+		status = http.StatusServiceUnavailable
+	}
+	c.mu.Unlock()
+	if status != 0 {
+		w.WriteHeader(status)
+	}
+}
+
+func (c *otlpCollector) snapshot() []otlpRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]otlpRequest(nil), c.requests...)
+}
+
+// waitForRequests 等待接收端收到至少 n 个请求
+func (c *otlpCollector) waitForRequests(n int) []otlpRequest {
+	deadline := time.Now().Add(2 * time.Second)
+	for len(c.snapshot()) < n && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return c.snapshot()
+}
+
+// otlpRecordCount 统计请求中的日志记录数
+func otlpRecordCount(req otlpRequest) int {
+	n := 0
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			n += len(sl.LogRecords)
+		}
+	}
+	return n
+}
+
+func TestOTLPWriter_BatchSize(t *testing.T) {
+	collector := &otlpCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	w, err := NewOTLPWriter(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create OTLP writer: %v", err)
+	}
+	w.SetBatchSize(3)
+	w.SetFlushInterval(0)
+	w.SetHeader("Authorization", "Bearer secret")
+
+	logger := NewLogger(w)
+	logger.SetFormatter(NewOTLPFormatter(WithServiceName("api")))
+	for i := 0; i < 4; i++ {
+		logger.Info("record", Int("i", i))
+	}
+
+	requests := collector.waitForRequests(1)
+	if len(requests) != 1 || otlpRecordCount(requests[0]) != 3 {
+		t.Fatalf("Expected one request with 3 records after the batch filled, got: %d", len(requests))
+	}
+	if len(requests[0].ResourceLogs) != 1 || len(requests[0].ResourceLogs[0].ScopeLogs) != 1 {
+		t.Errorf("Expected records with the same resource and scope to be merged")
+	}
+	if collector.paths[0] != "/v1/logs" {
+		t.Errorf("Expected default /v1/logs path, got: %s", collector.paths[0])
+	}
+	if collector.headers[0].Get("Authorization") != "Bearer secret" || collector.headers[0].Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected headers: %v", collector.headers[0])
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	requests = collector.snapshot()
+	if len(requests) != 2 || otlpRecordCount(requests[1]) != 1 {
+		t.Errorf("Expected Close to export the remaining record, got %d requests", len(requests))
+	}
+}
+
+func TestOTLPWriter_FlushInterval(t *testing.T) {
+	collector := &otlpCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	w, _ := NewOTLPWriter(server.URL + "/custom/logs")
+	defer w.Close()
+	w.SetFlushInterval(20 * time.Millisecond)
+
+	logger := NewLogger(w)
+	logger.SetFormatter(NewOTLPFormatter())
+	logger.Info("one")
+	logger.Warning("two")
+
+	requests := collector.waitForRequests(1)
+	if len(requests) != 1 || otlpRecordCount(requests[0]) != 2 {
+		t.Fatalf("Expected the interval flush to export 2 records, got: %d requests", len(requests))
+	}
+	if collector.paths[0] != "/custom/logs" {
+		t.Errorf("Expected explicit path to be kept, got: %s", collector.paths[0])
+	}
+}
+
+func TestOTLPWriter_ExportError(t *testing.T) {
+	collector := &otlpCollector{status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	w, _ := NewOTLPWriter(server.URL)
+	defer w.Close()
+	w.SetRetry(2, time.Millisecond)
+	logger := NewLogger(w)
+	logger.SetFormatter(NewOTLPFormatter())
+	logger.Info("lost")
+
+	if err := w.Flush(); err == nil {
+		t.Error("Expected Flush to report a non-2xx response")
+	}
+	if n := len(collector.snapshot()); n != 3 {
+		t.Errorf("Expected the export to be tried 3 times, got: %d", n)
+	}
+	if _, err := w.Write([]byte("not json")); err == nil {
+		t.Error("Expected an error for an invalid request")
+	}
+
+	collector.mu.Lock()
+	collector.status = http.StatusBadRequest
+	collector.mu.Unlock()
+	logger.Info("rejected")
+	if err := w.Flush(); err == nil {
+		t.Error("Expected Flush to report a rejected request")
+	}
+	if n := len(collector.snapshot()); n != 4 {
+		t.Errorf("Expected a 400 response not to be retried, got %d requests", n)
+	}
+}
+
+func TestOTLPWriter_RetriesUntilExported(t *testing.T) {
+	collector := &otlpCollector{failures: 2}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	w, _ := NewOTLPWriter(server.URL)
+	w.SetRetry(3, time.Millisecond)
+	logger := NewLogger(w)
+	logger.SetFormatter(NewOTLPFormatter())
+	logger.Info("kept")
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Expected the retried export to succeed, got: %v", err)
+	}
+	requests := collector.snapshot()
+	if len(requests) != 3 || otlpRecordCount(requests[2]) != 1 {
+		t.Errorf("Expected two failed attempts and one export, got %d requests", len(requests))
+	}
+	if _, err := w.Write([]byte(`{"resourceLogs":[]}`)); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed after Close, got: %v", err)
+	}
+}
+
+func TestOTLPWriter_WriteDoesNotWaitForExport(t *testing.T) {
+	collector := &otlpCollector{block: make(chan struct{})}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	w, _ := NewOTLPWriter(server.URL)
+	w.SetBatchSize(1)
+	logger := NewLogger(w)
+	logger.SetFormatter(NewOTLPFormatter())
+
+	written := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			logger.Info("record", Int("i", i))
+		}
+		close(written)
+	}()
+	select {
+	case <-written:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected writes to return while the collector is not responding")
+	}
+
+	close(collector.block)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	total := 0
+	for _, req := range collector.snapshot() {
+		total += otlpRecordCount(req)
+	}
+	if total != 10 {
+		t.Errorf("Expected all 10 records to be exported, got: %d", total)
+	}
+}
+
+func TestOTLPWriter_QueueFullDropsRecords(t *testing.T) {
+	collector := &otlpCollector{block: make(chan struct{})}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	w, _ := NewOTLPWriter(server.URL)
+	w.SetBatchSize(1)
+	w.SetFlushInterval(0)
+	logger := NewLogger(w)
+	logger.SetFormatter(NewOTLPFormatter())
+	for i := 0; i < otlpQueueBatches+10; i++ {
+		logger.Info("record")
+	}
+
+	close(collector.block)
+	err := w.Close()
+	if err == nil || !strings.Contains(err.Error(), "dropped") {
+		t.Errorf("Expected Close to report dropped records, got: %v", err)
+	}
+}
+
+func TestOTLPWriter_CloseTimeout(t *testing.T) {
+	collector := &otlpCollector{status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	w, _ := NewOTLPWriter(server.URL)
+	w.SetRetry(10, time.Hour)
+	w.SetShutdownTimeout(50 * time.Millisecond)
+	logger := NewLogger(w)
+	logger.SetFormatter(NewOTLPFormatter())
+	logger.Info("first")
+	logger.Info("second")
+
+	// 接收端一直不可用，Close 在超时后放弃重试并报告丢弃的记录，而不是等待一小时的退避
+	closed := make(chan error, 1)
+	go func() { closed <- w.Close() }()
+	select {
+	case err := <-closed:
+		if err == nil || !strings.Contains(err.Error(), "dropped 2 records because Close timed out") {
+			t.Errorf("Expected Close to report the dropped records, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Close to return after the shutdown timeout")
+	}
+}
+
+func TestNewOTLPWriter_InvalidEndpoint(t *testing.T) {
+	if _, err := NewOTLPWriter("grpc://localhost:4317"); err == nil {
+		t.Error("Expected an error for a non-HTTP endpoint")
+	}
+}
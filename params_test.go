@@ -30,7 +30,7 @@ func TestLoggerParams_TextFormatter(t *testing.T) {
 func TestLoggerParams_ColorFormatter(t *testing.T) {
 	var buf bytes.Buffer
 	logger := NewLogger(&buf)
-	logger.formatter = NewColorFormatter()
+	logger.SetFormatter(NewColorFormatter())
 
 	logger.Info("color param test", map[string]any{"a": 1, "b": 1.5, "c": true}, "d=xxx", 42)
 
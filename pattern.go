@@ -0,0 +1,241 @@
+package ygggo_log
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultPattern 与 TextFormatter 输出一致的默认布局
+const DefaultPattern = "%time{2006-01-02 15:04:05} [%level] %msg %fields"
+
+// patternVerb 布局中的占位符类型
+type patternVerb uint8
+
+const (
+	patternLiteral patternVerb = iota // 原样输出的文本
+	patternTime                       // %time{layout}
+	patternLevel                      // %level{width}
+	patternCaller                     // %caller{width}
+	patternMessage                    // %msg
+	patternFields                     // %fields
+)
+
+// patternSegment 布局解析后的一个片段
+type patternSegment struct {
+	verb  patternVerb
+	text  string      // 文本片段内容或时间布局
+	width int         // 对齐宽度，负数表示左对齐
+	time  timeEncoder // %time 使用的时间编码器
+}
+
+// PatternFormatter 按布局字符串输出文本日志，便于与已有的日志解析规则对齐。
+// 支持的占位符：
+//   - %time{layout}  记录时间，layout 为 Go 时间布局或 epoch；省略时使用格式化器的时间配置，
+//     默认 2006-01-02 15:04:05
+//   - %level{width}  日志级别，width 为对齐宽度，如 -7 表示左对齐补齐到 7 个字符
+//   - %caller{width} 调用位置 文件名:行号
+//   - %msg           日志消息
+//   - %fields        日志参数，key=value 以空格分隔
+//   - %%             百分号
+//
+// 每条日志末尾自动追加换行；%fields 为空时会去掉它前面紧邻的一个空格。
+type PatternFormatter struct {
+	pattern  string
+	segments []patternSegment
+	raw      bool // 不转义消息和参数
+}
+
+// NewPatternFormatter 解析布局字符串并创建格式化器，布局非法时返回错误。
+// 时区配置作用于所有 %time；布局和精度配置只作用于未显式指定布局的 %time。
+func NewPatternFormatter(pattern string, opts ...FormatterOption) (*PatternFormatter, error) {
+	segments, err := parsePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	o := newFormatterOptions(opts)
+	for i := range segments {
+		seg := &segments[i]
+		if seg.verb != patternTime {
+			continue
+		}
+		if seg.text == "" {
+			seg.time = newTimeEncoder(o, "2006-01-02 15:04:05")
+		} else {
+			seg.time = newTimeEncoder(formatterOptions{timeLayout: seg.text, timeLocation: o.timeLocation}, "")
+		}
+	}
+	return &PatternFormatter{pattern: pattern, segments: segments, raw: o.rawText}, nil
+}
+
+// Pattern 返回格式化器使用的布局字符串
+func (f *PatternFormatter) Pattern() string {
+	return f.pattern
+}
+
+// Format 按布局格式化
+func (f *PatternFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 按布局格式化
+func (f *PatternFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	for _, seg := range f.segments {
+		switch seg.verb {
+		case patternLiteral:
+			buf.b = append(buf.b, seg.text...)
+		case patternTime:
+			buf.b = seg.time.append(buf.b, e.Time)
+		case patternLevel:
+			buf.b = appendPadded(buf.b, e.Level.String(), seg.width)
+		case patternCaller:
+			file, line := e.Caller()
+			start := len(buf.b)
+			buf.b = append(buf.b, filepath.Base(file)...)
+			buf.b = append(buf.b, ':')
+			buf.b = strconv.AppendInt(buf.b, int64(line), 10)
+			buf.b = padTail(buf.b, start, seg.width)
+		case patternMessage:
+			if f.raw {
+				buf.b = append(buf.b, e.Message...)
+			} else {
+				buf.b = appendTextEscaped(buf.b, e.Message)
+			}
+		case patternFields:
+			if len(e.Fields) == 0 {
+				if n := len(buf.b); n > 0 && buf.b[n-1] == ' ' {
+					buf.b = buf.b[:n-1]
+				}
+				continue
+			}
+			if f.raw {
+				buf.b = appendTextFields(buf.b, e.Fields, appendRaw)
+			} else {
+				buf.b = appendEscapedTextFields(buf.b, e.Fields)
+			}
+		}
+	}
+	buf.b = append(buf.b, '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendPadded 追加 s 并按 width 补齐空格，width 为负数时左对齐
+func appendPadded(dst []byte, s string, width int) []byte {
+	start := len(dst)
+	dst = append(dst, s...)
+	return padTail(dst, start, width)
+}
+
+// padTail 将 dst[start:] 按 width 个显示列用空格补齐，width 为正数时右对齐；
+// 中文等全角字符按两列计算
+func padTail(dst []byte, start, width int) []byte {
+	size := len(dst) - start
+	n := displayWidth(dst[start:])
+	left := width < 0
+	if left {
+		width = -width
+	}
+	if n >= width {
+		return dst
+	}
+	pad := width - n
+	for i := 0; i < pad; i++ {
+		dst = append(dst, ' ')
+	}
+	if !left {
+		copy(dst[start+pad:], dst[start:start+size])
+		for i := start; i < start+pad; i++ {
+			dst[i] = ' '
+		}
+	}
+	return dst
+}
+
+// parsePattern 将布局字符串解析为片段列表
+func parsePattern(pattern string) ([]patternSegment, error) {
+	var segments []patternSegment
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			segments = append(segments, patternSegment{verb: patternLiteral, text: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		if c != '%' {
+			literal.WriteByte(c)
+			i++
+			continue
+		}
+		if i+1 < len(pattern) && pattern[i+1] == '%' {
+			literal.WriteByte('%')
+			i += 2
+			continue
+		}
+
+		// 读取占位符名称
+		j := i + 1
+		for j < len(pattern) && isPatternNameByte(pattern[j]) {
+			j++
+		}
+		name := pattern[i+1 : j]
+		if name == "" {
+			return nil, fmt.Errorf("invalid log pattern %q: missing verb after %% at offset %d", pattern, i)
+		}
+
+		// 读取可选的 {参数}
+		arg, hasArg := "", false
+		if j < len(pattern) && pattern[j] == '{' {
+			end := strings.IndexByte(pattern[j:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid log pattern %q: unterminated { after %%%s", pattern, name)
+			}
+			arg, hasArg = pattern[j+1:j+end], true
+			j += end + 1
+		}
+
+		seg := patternSegment{}
+		switch name {
+		case "time":
+			seg.verb = patternTime
+			seg.text = arg
+		case "level", "caller":
+			seg.verb = patternLevel
+			if name == "caller" {
+				seg.verb = patternCaller
+			}
+			if hasArg {
+				width, err := strconv.Atoi(arg)
+				if err != nil {
+					return nil, fmt.Errorf("invalid log pattern %q: bad width %q for %%%s", pattern, arg, name)
+				}
+				seg.width = width
+			}
+		case "msg":
+			seg.verb = patternMessage
+		case "fields":
+			seg.verb = patternFields
+		default:
+			return nil, fmt.Errorf("invalid log pattern %q: unknown verb %%%s", pattern, name)
+		}
+		if hasArg && (seg.verb == patternMessage || seg.verb == patternFields) {
+			return nil, fmt.Errorf("invalid log pattern %q: %%%s takes no argument", pattern, name)
+		}
+
+		flush()
+		segments = append(segments, seg)
+		i = j
+	}
+	flush()
+	return segments, nil
+}
+
+func isPatternNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
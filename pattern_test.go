@@ -0,0 +1,186 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPatternFormatter_Layout(t *testing.T) {
+	f, err := NewPatternFormatter("%time{2006-01-02T15:04:05.000} %level{-7} %msg %fields")
+	if err != nil {
+		t.Fatalf("Failed to parse pattern: %v", err)
+	}
+
+	e := &Entry{
+		Time:    time.Date(2025, 3, 4, 5, 6, 7, 891000000, time.UTC),
+		Level:   InfoLevel,
+		Message: "hello",
+		Fields:  []Field{String("user", "tom"), Int("n", 2)},
+	}
+	var buf bytes.Buffer
+	f.FormatEntry(&buf, e)
+
+	expected := "2025-03-04T05:06:07.891 INFO    hello user=tom n=2\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestPatternFormatter_EmptyFieldsAndPadding(t *testing.T) {
+	f, err := NewPatternFormatter("[%level{5}] %msg %fields|100%%")
+	if err != nil {
+		t.Fatalf("Failed to parse pattern: %v", err)
+	}
+
+	var buf bytes.Buffer
+	f.FormatEntry(&buf, &Entry{Level: ErrorLevel, Message: "no fields"})
+
+	expected := "[ERROR] no fields|100%\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	f.FormatEntry(&buf, &Entry{Level: InfoLevel, Message: "x"})
+	if !strings.HasPrefix(buf.String(), "[ INFO] ") {
+		t.Errorf("Expected right-aligned level, got: %q", buf.String())
+	}
+}
+
+func TestPatternFormatter_Caller(t *testing.T) {
+	f, err := NewPatternFormatter("%caller %msg")
+	if err != nil {
+		t.Fatalf("Failed to parse pattern: %v", err)
+	}
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(f)
+
+	logger.Info("with caller")
+
+	if !strings.HasPrefix(buf.String(), "pattern_test.go:") {
+		t.Errorf("Expected caller prefix, got: %q", buf.String())
+	}
+}
+
+func TestPatternFormatter_Invalid(t *testing.T) {
+	patterns := []string{
+		"%unknown",
+		"%time{2006",
+		"%level{abc}",
+		"%msg{1}",
+		"trailing %",
+	}
+	for _, p := range patterns {
+		if _, err := NewPatternFormatter(p); err == nil {
+			t.Errorf("Expected error for pattern %q", p)
+		}
+	}
+}
+
+func TestPatternFormatter_DefaultMatchesText(t *testing.T) {
+	f, err := NewPatternFormatter(DefaultPattern)
+	if err != nil {
+		t.Fatalf("Failed to parse default pattern: %v", err)
+	}
+	e := &Entry{Time: time.Now(), Level: WarningLevel, Message: "same", Fields: []Field{Bool("ok", true)}}
+
+	var pattern, text bytes.Buffer
+	f.FormatEntry(&pattern, e)
+	NewTextFormatter().FormatEntry(&text, e)
+
+	if pattern.String() != text.String() {
+		t.Errorf("Expected default pattern to match TextFormatter, got %q vs %q", pattern.String(), text.String())
+	}
+}
+
+func TestLoadConfigFromEnv_Pattern(t *testing.T) {
+	os.Setenv("YGGGO_LOG_PATTERN", "%level %msg")
+	defer os.Unsetenv("YGGGO_LOG_PATTERN")
+
+	var buf bytes.Buffer
+	logger := NewLoggerFromEnvWithOutput(&buf)
+	logger.Info("from env")
+
+	if buf.String() != "INFO from env\n" {
+		t.Errorf("Expected pattern output, got: %q", buf.String())
+	}
+}
+
+func TestLoggerFromConfig_PatternFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "pattern.log")
+	config := &LogConfig{
+		Level:       InfoLevel,
+		OutputFile:  logFile,
+		Format:      TextFormat,
+		FileSize:    1024 * 1024,
+		FileNum:     2,
+		Pattern:     "%level %msg",
+		FilePattern: "%level|%msg",
+	}
+
+	logger := NewLoggerFromConfig(config)
+	logger.Warning("to file")
+	logger.Close()
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if string(content) != "WARNING|to file\n" {
+		t.Errorf("Expected the file pattern in the file, got: %q", content)
+	}
+}
+
+func TestLoggerFromConfig_PatternConsoleOnly(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "json.log")
+	config := &LogConfig{
+		Level:      InfoLevel,
+		OutputFile: logFile,
+		Format:     TextFormat,
+		FileSize:   1024 * 1024,
+		FileNum:    2,
+		Pattern:    "%level|%msg",
+		ColorMode:  ColorNever,
+	}
+
+	var buf bytes.Buffer
+	console := createConsoleFormatter(config, &buf)
+	console.Format(&buf, InfoLevel, "to console")
+	if buf.String() != "INFO|to console\n" {
+		t.Errorf("Expected the pattern on the console, got: %q", buf.String())
+	}
+
+	logger := NewLoggerFromConfig(config)
+	logger.Warning("to file")
+	logger.Close()
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	var entry JsonLogEntry
+	if err := json.Unmarshal(content, &entry); err != nil || entry.Message != "to file" {
+		t.Errorf("Expected JSON in the file when only the console pattern is set, got: %q", content)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidPattern(t *testing.T) {
+	os.Setenv("YGGGO_LOG_PATTERN", "%bogus")
+	defer os.Unsetenv("YGGGO_LOG_PATTERN")
+	os.Setenv("YGGGO_LOG_FILE_PATTERN", "%level|%msg")
+	defer os.Unsetenv("YGGGO_LOG_FILE_PATTERN")
+
+	config := LoadConfigFromEnv()
+	if config.Pattern != "" || len(config.Errors) != 1 {
+		t.Errorf("Expected the invalid pattern to be dropped and reported, got %q and %v", config.Pattern, config.Errors)
+	}
+	if config.FilePattern != "%level|%msg" {
+		t.Errorf("Expected the file pattern to be loaded, got: %q", config.FilePattern)
+	}
+}
@@ -0,0 +1,264 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// PrettyFormatter 的排版参数
+const (
+	prettyLevelWidth     = 7   // 级别列宽度，与最长的 WARNING 对齐
+	prettyCallerWidth    = 20  // 调用位置列宽度
+	prettyLineWidth      = 120 // 参数跟在消息后面时整行的最大显示宽度
+	prettyInlineFields   = 4   // 参数跟在消息后面时的最大个数
+	prettyCompactWidth   = 60  // 嵌套值紧凑输出的最大宽度，超过时缩进展开
+	prettyIndent         = "    "
+	prettyValueSeparator = " = "
+)
+
+// PrettyFormatter 面向开发环境的彩色控制台格式化器。级别和调用位置补齐到固定宽度，
+// 参数较少且较短时跟在消息后面，否则每个参数单独缩进一行并对齐键名：
+//
+//	15:04:05.000 INFO    handler.go:42        user login  user=tom status=200
+//	15:04:05.000 ERROR   handler.go:57        request failed
+//	    error   = connection refused
+//	    payload = {
+//	                "id": 7,
+//	                "note": "a fairly long description of the request payload"
+//	              }
+//
+// map、结构体等嵌套值和 JSON 字符串会格式化输出，多行消息、多行参数值（如堆栈）
+// 逐行缩进。宽度按显示宽度计算，中文等全角字符占两列，因此中文消息也能对齐。
+type PrettyFormatter struct {
+	time    timeEncoder
+	palette *palette
+	link    *callerLink
+	path    bool // 调用位置显示相对路径
+	raw     bool // 不转义消息和参数
+}
+
+// NewPrettyFormatter 创建开发环境格式化器，默认时间布局为 15:04:05.000，
+// 配色通过 WithTheme 设置，调用位置链接通过 WithCallerLink 设置
+func NewPrettyFormatter(opts ...FormatterOption) *PrettyFormatter {
+	o := newFormatterOptions(opts)
+	return &PrettyFormatter{
+		time:    newTimeEncoder(o, "15:04:05.000"),
+		palette: o.palette(),
+		link:    newCallerLink(o.callerLink),
+		path:    o.callerPath,
+		raw:     o.rawText,
+	}
+}
+
+// Format 格式化为开发环境的控制台格式
+func (f *PrettyFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为开发环境的控制台格式
+func (f *PrettyFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	p := f.palette
+	buf := getBuffer()
+	buf.b = append(buf.b, p.time...)
+	buf.b = f.time.append(buf.b, e.Time)
+	buf.b = appendReset(buf.b, p.time)
+	buf.b = append(buf.b, ' ')
+	levelColor := p.level(e.Level)
+	buf.b = append(buf.b, levelColor...)
+	buf.b = appendPadded(buf.b, e.Level.String(), -prettyLevelWidth)
+	buf.b = appendReset(buf.b, levelColor)
+	buf.b = append(buf.b, ' ')
+
+	frame := e.CallerFrame()
+	buf.b = append(buf.b, p.caller...)
+	start := len(buf.b)
+	buf.b = f.link.appendOpen(buf.b, frame)
+	buf.b = appendCaller(buf.b, frame, f.path)
+	buf.b = f.link.appendClose(buf.b, frame)
+	buf.b = padTail(buf.b, start, -prettyCallerWidth)
+	buf.b = appendReset(buf.b, p.caller)
+	buf.b = append(buf.b, ' ')
+
+	// 消息的第一行跟在调用位置后面，其余各行缩进
+	msg, rest := cutLine(e.Message)
+	buf.b = appendPrettyText(buf.b, msg, f.raw)
+	width := displayWidth(buf.b)
+	for rest != "" {
+		msg, rest = cutLine(rest)
+		buf.b = append(buf.b, '\n')
+		buf.b = append(buf.b, prettyIndent...)
+		buf.b = appendPrettyText(buf.b, msg, f.raw)
+		width = prettyLineWidth // 多行消息的参数不再跟在消息后面
+	}
+
+	scratch := getBuffer()
+	if f.fitsInline(scratch, e.Fields, width) {
+		buf.b = f.appendInlineFields(buf.b, scratch, e.Fields)
+	} else {
+		buf.b = f.appendFieldLines(buf.b, scratch, e.Fields)
+	}
+	putBuffer(scratch)
+
+	buf.b = append(buf.b, '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// fitsInline 判断参数能否跟在消息后面：个数不多、值都是单行且整行不超过 prettyLineWidth
+func (f *PrettyFormatter) fitsInline(scratch *buffer, fields []Field, width int) bool {
+	if len(fields) > prettyInlineFields {
+		return false
+	}
+	width += 1 // 消息与参数之间多一个空格
+	positional := 0
+	for _, field := range fields {
+		scratch.b = f.appendKey(scratch.b[:0], field, &positional)
+		width += 2 + displayWidth(scratch.b) // 空格和等号
+		scratch.b = appendPrettyValue(scratch.b[:0], field)
+		if bytes.IndexByte(scratch.b, '\n') >= 0 {
+			return false
+		}
+		width += displayWidth(scratch.b)
+	}
+	return width <= prettyLineWidth
+}
+
+// appendInlineFields 在消息后面追加 key=value 形式的参数
+func (f *PrettyFormatter) appendInlineFields(dst []byte, scratch *buffer, fields []Field) []byte {
+	positional := 0
+	for i, field := range fields {
+		if i == 0 {
+			dst = append(dst, ' ')
+		}
+		dst = append(dst, ' ')
+		dst = append(dst, f.palette.key...)
+		dst = f.appendKey(dst, field, &positional)
+		dst = appendReset(dst, f.palette.key)
+		dst = append(dst, '=')
+		scratch.b = appendPrettyValue(scratch.b[:0], field)
+		color := f.palette.value(field.Type)
+		dst = append(dst, color...)
+		dst = appendPrettyText(dst, scratch.b, f.raw)
+		dst = appendReset(dst, color)
+	}
+	return dst
+}
+
+// appendFieldLines 每个参数单独一行，键名补齐到相同宽度，多行的值与第一行对齐
+func (f *PrettyFormatter) appendFieldLines(dst []byte, scratch *buffer, fields []Field) []byte {
+	keyWidth := 0
+	positional := 0
+	for _, field := range fields {
+		scratch.b = f.appendKey(scratch.b[:0], field, &positional)
+		keyWidth = max(keyWidth, displayWidth(scratch.b))
+	}
+
+	positional = 0
+	for _, field := range fields {
+		dst = append(dst, '\n')
+		dst = append(dst, prettyIndent...)
+		dst = append(dst, f.palette.key...)
+		start := len(dst)
+		dst = f.appendKey(dst, field, &positional)
+		dst = padTail(dst, start, -keyWidth)
+		dst = appendReset(dst, f.palette.key)
+		dst = append(dst, prettyValueSeparator...)
+
+		scratch.b = appendPrettyValue(scratch.b[:0], field)
+		color := f.palette.value(field.Type)
+		value := scratch.b
+		for {
+			line, rest, more := bytes.Cut(value, []byte{'\n'})
+			dst = append(dst, color...)
+			dst = appendPrettyText(dst, line, f.raw)
+			dst = appendReset(dst, color)
+			if !more {
+				break
+			}
+			dst = append(dst, '\n')
+			dst = append(dst, prettyIndent...)
+			dst = appendSpaces(dst, keyWidth+len(prettyValueSeparator))
+			value = rest
+		}
+	}
+	return dst
+}
+
+// appendKey 追加参数键名，位置参数为 arg0、arg1……；除非配置了 WithoutEscaping，控制字符会被转义
+func (f *PrettyFormatter) appendKey(dst []byte, field Field, positional *int) []byte {
+	if f.raw {
+		return appendFieldKey(dst, field, positional, appendRaw)
+	}
+	return appendFieldKey(dst, field, positional, appendTextEscaped)
+}
+
+// appendPrettyText 追加单行文本，raw 为 false 时转义控制字符
+func appendPrettyText[T string | []byte](dst []byte, s T, raw bool) []byte {
+	if raw {
+		return append(dst, s...)
+	}
+	return appendEscaped(dst, s, false)
+}
+
+// appendPrettyValue 追加便于阅读的参数值：错误使用 %+v（可带出堆栈），
+// 嵌套值和 JSON 字符串较短时紧凑输出，较长时缩进展开
+func appendPrettyValue(dst []byte, f Field) []byte {
+	switch f.Type {
+	case StringType:
+		if s := f.str; len(s) > 1 && (s[0] == '{' || s[0] == '[') && json.Valid([]byte(s)) {
+			return appendPrettyJSON(dst, []byte(s))
+		}
+		return append(dst, f.str...)
+	case ErrorType:
+		return fmt.Appendf(dst, "%+v", f.iface)
+	case AnyType:
+		switch v := f.iface.(type) {
+		case nil:
+			return append(dst, "<nil>"...)
+		case error:
+			return fmt.Appendf(dst, "%+v", v)
+		case fmt.Stringer:
+			return append(dst, v.String()...)
+		}
+		if data, err := json.Marshal(f.iface); err == nil && (len(data) == 0 || data[0] == '{' || data[0] == '[') {
+			return appendPrettyJSON(dst, data)
+		}
+		return fmt.Appendf(dst, "%+v", f.iface)
+	default:
+		return appendFieldValue(dst, f)
+	}
+}
+
+// appendPrettyJSON 追加 JSON：紧凑形式不超过 prettyCompactWidth 时原样输出，否则缩进两格展开
+func appendPrettyJSON(dst []byte, data []byte) []byte {
+	start := len(dst)
+	out := bytes.NewBuffer(dst)
+	if json.Compact(out, data) == nil && out.Len()-start <= prettyCompactWidth {
+		return out.Bytes()
+	}
+	out.Truncate(start)
+	if json.Indent(out, data, "", "  ") != nil {
+		return append(dst[:start], data...)
+	}
+	return out.Bytes()
+}
+
+// cutLine 返回第一行和剩余部分
+func cutLine(s string) (string, string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			return s[:i], s[i+1:]
+		}
+	}
+	return s, ""
+}
+
+// appendSpaces 追加 n 个空格
+func appendSpaces(dst []byte, n int) []byte {
+	for ; n > 0; n-- {
+		dst = append(dst, ' ')
+	}
+	return dst
+}
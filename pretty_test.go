@@ -0,0 +1,118 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// ansiPattern 匹配 ANSI 颜色序列
+var ansiPattern = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// prettyLines 记录一条日志并返回去掉颜色后的各行
+func prettyLines(t *testing.T, log func(l *Logger)) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewPrettyFormatter())
+	log(logger)
+	return strings.Split(strings.TrimSuffix(ansiPattern.ReplaceAllString(buf.String(), ""), "\n"), "\n")
+}
+
+func TestPrettyFormatter_InlineFields(t *testing.T) {
+	lines := prettyLines(t, func(l *Logger) { l.Info("user login", "user=tom", Int("status", 200)) })
+
+	if len(lines) != 1 {
+		t.Fatalf("Expected a single line, got: %q", lines)
+	}
+	if !regexp.MustCompile(`^08:09:10\.123 INFO    pretty_test\.go:\d+ +user login  user=tom status=200$`).MatchString(lines[0]) {
+		t.Errorf("Unexpected layout: %q", lines[0])
+	}
+}
+
+func TestPrettyFormatter_AlignedColumns(t *testing.T) {
+	lines := prettyLines(t, func(l *Logger) {
+		l.Info("english")
+		l.Warning("中文消息")
+	})
+
+	// 级别和调用位置补齐后，两条消息从同一列开始
+	col := func(line, msg string) int { return displayWidth([]byte(line[:strings.Index(line, msg)])) }
+	if col(lines[0], "english") != col(lines[1], "中文消息") {
+		t.Errorf("Expected messages to start in the same column:\n%s\n%s", lines[0], lines[1])
+	}
+}
+
+func TestPrettyFormatter_FieldLines(t *testing.T) {
+	lines := prettyLines(t, func(l *Logger) {
+		l.Error("request failed",
+			Err(errors.New("connection refused")),
+			String("用户", "张三"),
+			Any("payload", map[string]any{"id": 7, "note": "a fairly long description of the request payload"}),
+			String("body", `{"a":1}`),
+			Int("n", 5),
+		)
+	})
+
+	want := []string{
+		"    error   = connection refused",
+		"    用户    = 张三",
+		"    payload = {",
+		`                "id": 7,`,
+		`                "note": "a fairly long description of the request payload"`,
+		"              }",
+		`    body    = {"a":1}`,
+		"    n       = 5",
+	}
+	if strings.Join(lines[1:], "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected field lines:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestPrettyFormatter_MultilineMessage(t *testing.T) {
+	lines := prettyLines(t, func(l *Logger) {
+		l.Error("panic recovered\ngoroutine 1 [running]:", String("stack", "main.main()\n\tmain.go:10"))
+	})
+
+	want := []string{
+		"    goroutine 1 [running]:",
+		"    stack = main.main()",
+		"            \tmain.go:10",
+	}
+	if !strings.HasSuffix(lines[0], "panic recovered") || strings.Join(lines[1:], "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected multi-line layout:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := map[string]int{
+		"abc":                       3,
+		"中文":                        4,
+		"ｈｉ":                        4,
+		"é":                        1,
+		ColorRed + "x" + ColorReset: 1,
+	}
+	for s, want := range tests {
+		if got := displayWidth([]byte(s)); got != want {
+			t.Errorf("displayWidth(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestLoggerFromEnv_PrettyFormat(t *testing.T) {
+	os.Setenv("YGGGO_LOG_FORMAT", "pretty")
+	defer os.Unsetenv("YGGGO_LOG_FORMAT")
+
+	var buf bytes.Buffer
+	logger := NewLoggerFromEnvWithOutput(&buf)
+	if _, ok := logger.formatter.(*PrettyFormatter); !ok {
+		t.Errorf("Expected PrettyFormatter, got: %T", logger.formatter)
+	}
+	if _, ok := createFileFormatter(&LogConfig{Format: PrettyFormat}).(*JsonFormatter); !ok {
+		t.Errorf("Expected JSON files for the pretty format")
+	}
+}
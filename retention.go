@@ -0,0 +1,84 @@
+package ygggo_log
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention limits how much rotated log data a RotatingWriter keeps, in
+// addition to its file count. Zero values disable a limit.
+type Retention struct {
+	// MaxAge removes rotated files last modified longer ago than this.
+	MaxAge time.Duration
+	// MaxTotalSize bounds the bytes used by the rotated files, counting the
+	// compressed size of .gz files. The oldest files are removed first; the
+	// most recently rotated file is always kept.
+	MaxTotalSize int64
+}
+
+// SetRetention sets the age and total size limits for rotated files. They are
+// enforced immediately on the files already on disk, so stale files from
+// earlier deployments are cleaned up on startup, and again at every rotation.
+func (rw *RotatingWriter) SetRetention(retention Retention) {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	rw.retention = retention
+	if files, err := rw.getRotatedFiles(); err == nil {
+		rw.applyRetention(files, 0)
+	}
+}
+
+// applyRetention 删除超过保留时间或总大小的轮转文件，返回保留的文件。
+// files 按索引从新到旧排列；pending 为即将轮转为 .1 的当前文件大小，计入总大小
+func (rw *RotatingWriter) applyRetention(files []rotatedFile, pending int64) []rotatedFile {
+	r := rw.retention
+	if r == (Retention{}) {
+		return files
+	}
+	cutoff := time.Time{}
+	if r.MaxAge > 0 {
+		cutoff = rw.now().Add(-r.MaxAge)
+	}
+
+	total := pending
+	full := false
+	kept := files[:0]
+	for i, f := range files {
+		expired := !cutoff.IsZero() && f.modTime.Before(cutoff)
+		// 超出总大小后更旧的文件全部删除；没有待轮转的文件时，最新的轮转文件不受总大小限制
+		full = full || r.MaxTotalSize > 0 && total+f.size > r.MaxTotalSize && (pending > 0 || i > 0)
+		if expired || full {
+			rw.removeRotated(f)
+			continue
+		}
+		total += f.size
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// parseAge 解析保留时间：7d、2w 或 time.ParseDuration 支持的格式（如 36h），格式无效或为负数时 ok 为 false
+func parseAge(ageStr string) (age time.Duration, ok bool) {
+	ageStr = strings.TrimSpace(strings.ToLower(ageStr))
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(ageStr, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(ageStr, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(ageStr[:len(ageStr)-1])
+		if err != nil || n < 0 || time.Duration(n) > math.MaxInt64/unit {
+			return 0, false
+		}
+		return time.Duration(n) * unit, true
+	}
+	d, err := time.ParseDuration(ageStr)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+	return d, true
+}
@@ -0,0 +1,176 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeRotated 创建内容为 size 字节、修改时间为 modTime 的文件
+func writeRotated(t *testing.T, path string, size int, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, make([]byte, size), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// listDir 返回目录中的文件名
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotatingWriter_RetentionMaxAgeOnStartup(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	now := time.Now()
+	writeRotated(t, filename+".1", 10, now.Add(-24*time.Hour))
+	writeRotated(t, filename+".2.gz", 10, now.Add(-10*24*time.Hour))
+	writeRotated(t, filename+".3", 10, now.Add(-20*24*time.Hour))
+
+	rw, err := NewRotatingWriter(filename, 0, 10)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	defer rw.Close()
+	rw.SetRetention(Retention{MaxAge: 7 * 24 * time.Hour})
+
+	if names := listDir(t, dir); len(names) != 2 || names[0] != "app.log" || names[1] != "app.log.1" {
+		t.Errorf("Expected files older than 7 days to be removed, got: %v", names)
+	}
+}
+
+func TestRotatingWriter_RetentionTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	rw, err := NewRotatingWriter(filename, 100, 10)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	defer rw.Close()
+	rw.SetRetention(Retention{MaxTotalSize: 250})
+
+	line := make([]byte, 99)
+	line[98] = '\n'
+	for i := 0; i < 6; i++ {
+		rw.Write(line)
+	}
+
+	// 每个轮转文件 99 字节，250 字节内最多保留 2 个
+	names := listDir(t, dir)
+	if len(names) != 3 || names[1] != "app.log.1" || names[2] != "app.log.2" {
+		t.Errorf("Expected app.log, app.log.1 and app.log.2, got: %v", names)
+	}
+}
+
+func TestRotatingWriter_RetentionTotalSizeKeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	now := time.Now()
+	writeRotated(t, filename+".1", 500, now)
+	writeRotated(t, filename+".2", 50, now)
+
+	rw, err := NewRotatingWriter(filename, 0, 10)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	defer rw.Close()
+	rw.SetRetention(Retention{MaxTotalSize: 100})
+
+	// 最新的轮转文件即使超过总大小也会保留，更旧的文件全部删除
+	if names := listDir(t, dir); len(names) != 2 || names[1] != "app.log.1" {
+		t.Errorf("Expected only the newest rotated file to remain, got: %v", names)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"7d":   7 * 24 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"36h":  36 * time.Hour,
+		" 1D ": 24 * time.Hour,
+		"0":    0,
+	}
+	for input, want := range testCases {
+		if got, ok := parseAge(input); !ok || got != want {
+			t.Errorf("parseAge(%q): expected %v, got: %v (ok=%v)", input, want, got, ok)
+		}
+	}
+	for _, input := range []string{"", "invalid", "-3d", "7 days", "99999999999999w"} {
+		if _, ok := parseAge(input); ok {
+			t.Errorf("parseAge(%q): expected an invalid age", input)
+		}
+	}
+}
+
+func TestLoadConfigFromEnv_Retention(t *testing.T) {
+	os.Setenv("YGGGO_LOG_FILE_MAX_AGE", "7d")
+	os.Setenv("YGGGO_LOG_TOTAL_SIZE", "2G")
+	defer os.Unsetenv("YGGGO_LOG_FILE_MAX_AGE")
+	defer os.Unsetenv("YGGGO_LOG_TOTAL_SIZE")
+
+	config := LoadConfigFromEnv()
+	if config.MaxAge != 7*24*time.Hour || config.TotalSize != 2<<30 {
+		t.Errorf("Unexpected retention: %v %d", config.MaxAge, config.TotalSize)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidTotalSize(t *testing.T) {
+	for _, value := range []string{"2GiB", "2 G", "-1G", "lots"} {
+		os.Setenv("YGGGO_LOG_TOTAL_SIZE", value)
+		config := LoadConfigFromEnv()
+		if config.TotalSize != 0 {
+			t.Errorf("Expected %q to disable the total size limit, got: %d", value, config.TotalSize)
+		}
+		if len(config.Errors) != 1 || !strings.Contains(config.Errors[0].Error(), "YGGGO_LOG_TOTAL_SIZE") {
+			t.Errorf("Expected a config error for %q, got: %v", value, config.Errors)
+		}
+	}
+	os.Unsetenv("YGGGO_LOG_TOTAL_SIZE")
+
+	if config := LoadConfigFromEnv(); len(config.Errors) != 0 {
+		t.Errorf("Expected no config errors by default, got: %v", config.Errors)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidMaxAge(t *testing.T) {
+	os.Setenv("YGGGO_LOG_FILE_MAX_AGE", "7days")
+	defer os.Unsetenv("YGGGO_LOG_FILE_MAX_AGE")
+
+	config := LoadConfigFromEnv()
+	if config.MaxAge != 0 {
+		t.Errorf("Expected an invalid age to disable the limit, got: %v", config.MaxAge)
+	}
+	if len(config.Errors) != 1 || !strings.Contains(config.Errors[0].Error(), "YGGGO_LOG_FILE_MAX_AGE") {
+		t.Errorf("Expected a config error, got: %v", config.Errors)
+	}
+}
+
+func TestNewLoggerFromEnvWithOutput_ReportsConfigErrors(t *testing.T) {
+	os.Setenv("YGGGO_LOG_TOTAL_SIZE", "2GiB")
+	os.Setenv("YGGGO_LOG_LEVEL", "ERROR")
+	defer os.Unsetenv("YGGGO_LOG_TOTAL_SIZE")
+	defer os.Unsetenv("YGGGO_LOG_LEVEL")
+
+	var buf bytes.Buffer
+	NewLoggerFromEnvWithOutput(&buf)
+	if out := buf.String(); !strings.Contains(out, "[WARNING] ignored invalid logging configuration") || !strings.Contains(out, "2GiB") {
+		t.Errorf("Expected a warning about YGGGO_LOG_TOTAL_SIZE, got: %q", out)
+	}
+}
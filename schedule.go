@@ -0,0 +1,145 @@
+package ygggo_log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when a RotatingWriter starts a new file based on time.
+type Schedule interface {
+	// Next returns the first rotation time strictly after t, or the zero time
+	// when the schedule never fires again.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses a rotation schedule evaluated in loc (nil means local
+// time). It accepts hourly, daily and weekly (Monday 00:00), with or without a
+// leading @, or a five-field cron expression "minute hour day-of-month month
+// day-of-week" supporting *, lists (1,15), ranges (1-5) and steps (*/6).
+// As in cron, when both day fields are restricted either one may match.
+func ParseSchedule(spec string, loc *time.Location) (Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	switch strings.ToLower(strings.TrimPrefix(expr, "@")) {
+	case "hourly":
+		expr = "0 * * * *"
+	case "daily", "midnight":
+		expr = "0 0 * * *"
+	case "weekly":
+		expr = "0 0 * * 1"
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid rotation schedule %q: expected hourly, daily, weekly or 5 cron fields", spec)
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	s := &cronSchedule{loc: loc}
+	fields := []struct {
+		set      *uint64
+		min, max int
+	}{
+		{&s.minute, 0, 59}, {&s.hour, 0, 23}, {&s.dom, 1, 31}, {&s.month, 1, 12}, {&s.dow, 0, 7},
+	}
+	for i, f := range fields {
+		set, err := parseCronField(parts[i], f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rotation schedule %q: %w", spec, err)
+		}
+		*f.set = set
+	}
+	if s.dow&(1<<7) != 0 { // 7 与 0 都表示星期日
+		s.dow |= 1
+	}
+	s.domAny = parts[2] == "*"
+	s.dowAny = parts[4] == "*"
+	return s, nil
+}
+
+// cronSchedule 解析后的 cron 表达式，各字段以位集合表示允许的取值
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+	loc                           *time.Location
+}
+
+// Next 返回 t 之后的下一个匹配时刻，逐级跳过不匹配的月、日、小时和分钟；五年内没有匹配时返回零值。
+// 起点按绝对时间前进到下一分钟，按日历字段跳转的结果若因夏令时回拨不晚于当前时刻则改为前进一分钟，
+// 保证返回值总是晚于 t
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	cur := t.Truncate(time.Minute).Add(time.Minute).In(s.loc)
+	limit := cur.Year() + 5
+	for cur.Year() <= limit {
+		var next time.Time
+		switch {
+		case s.month&(1<<uint(cur.Month())) == 0:
+			next = time.Date(cur.Year(), cur.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(cur):
+			next = time.Date(cur.Year(), cur.Month(), cur.Day()+1, 0, 0, 0, 0, s.loc)
+		case s.hour&(1<<uint(cur.Hour())) == 0:
+			next = time.Date(cur.Year(), cur.Month(), cur.Day(), cur.Hour()+1, 0, 0, 0, s.loc)
+		case s.minute&(1<<uint(cur.Minute())) == 0:
+			next = cur.Add(time.Minute)
+		default:
+			return cur
+		}
+		if !next.After(cur) {
+			next = cur.Add(time.Minute)
+		}
+		cur = next
+	}
+	return time.Time{}
+}
+
+// dayMatches 判断日期是否匹配：两个日字段都有限制时满足其一即可，否则都需满足
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if !s.domAny && !s.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// parseCronField 解析一个 cron 字段，返回允许取值的位集合
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value out of range %d-%d in %q", min, max, part)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
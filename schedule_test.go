@@ -0,0 +1,238 @@
+package ygggo_log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	// 2025-03-05 是星期三
+	from := time.Date(2025, 3, 5, 10, 30, 15, 0, time.UTC)
+
+	testCases := []struct {
+		spec string
+		loc  *time.Location
+		want time.Time
+	}{
+		{"hourly", time.UTC, time.Date(2025, 3, 5, 11, 0, 0, 0, time.UTC)},
+		{"daily", time.UTC, time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"@daily", shanghai, time.Date(2025, 3, 6, 0, 0, 0, 0, shanghai)},
+		{"weekly", time.UTC, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.UTC, time.Date(2025, 3, 5, 10, 45, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.UTC, time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.UTC, time.Date(2025, 4, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.UTC, time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 5", time.UTC, time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)},   // 任一日字段匹配即可
+		{"0 0 29 2 *", time.UTC, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)}, // 闰日
+		{"0 9-17 * * 1-5", time.UTC, time.Date(2025, 3, 5, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tc.spec, tc.loc)
+			if err != nil {
+				t.Fatalf("ParseSchedule failed: %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tc.want) {
+				t.Errorf("Expected next rotation %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSchedule_DaylightSaving(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	hourly, _ := ParseSchedule("hourly", newYork)
+
+	// 2025-11-02 01:00-02:00 出现两次：先是 EDT（UTC-4），再是 EST（UTC-5）
+	firstPass := time.Date(2025, 11, 2, 5, 30, 0, 0, time.UTC)  // 01:30 EDT
+	secondPass := time.Date(2025, 11, 2, 6, 30, 0, 0, time.UTC) // 01:30 EST
+	if got, want := hourly.Next(firstPass), time.Date(2025, 11, 2, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Expected 01:00 EST after 01:30 EDT, got: %v", got)
+	}
+	if got, want := hourly.Next(secondPass), time.Date(2025, 11, 2, 7, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Expected 02:00 EST after 01:30 EST, got: %v", got)
+	}
+
+	// 夏令时切换前后的每一分钟，下一次轮转都必须晚于当前时刻
+	for _, spec := range []string{"hourly", "daily", "*/15 * * * *", "30 1 * * *", "30 2 * * *"} {
+		s, _ := ParseSchedule(spec, newYork)
+		for _, start := range []time.Time{
+			time.Date(2025, 11, 2, 4, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC),
+		} {
+			for m := 0; m < 4*60; m++ {
+				now := start.Add(time.Duration(m)*time.Minute + 17*time.Second)
+				if next := s.Next(now); !next.After(now) {
+					t.Fatalf("%s: Next(%v) = %v is not after it", spec, now.In(newYork), next.In(newYork))
+				}
+			}
+		}
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "monthly", "* * * *", "60 * * * *", "0 24 * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseSchedule(spec, nil); err == nil {
+			t.Errorf("Expected error for schedule %q", spec)
+		}
+	}
+}
+
+func TestParseSchedule_Never(t *testing.T) {
+	s, err := ParseSchedule("0 0 31 2 *", time.UTC)
+	if err != nil {
+		t.Fatalf("ParseSchedule failed: %v", err)
+	}
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Expected no rotation for February 31st, got: %v", next)
+	}
+}
+
+// scheduledWriter 创建按天轮转的 RotatingWriter，时钟由 now 控制
+func scheduledWriter(t *testing.T, filename string, maxSize int64, now *time.Time) *RotatingWriter {
+	t.Helper()
+	rw, err := NewRotatingWriter(filename, maxSize, 5)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter failed: %v", err)
+	}
+	rw.now = func() time.Time { return *now }
+	schedule, _ := ParseSchedule("daily", time.UTC)
+	rw.SetSchedule(schedule)
+	return rw
+}
+
+func TestRotatingWriter_DailyRotation(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	now := time.Date(2025, 3, 5, 23, 59, 0, 0, time.UTC)
+	rw := scheduledWriter(t, filename, 0, &now)
+	defer rw.Close()
+
+	rw.Write([]byte("day1\n"))
+	now = now.Add(2 * time.Minute)
+	if !rw.WillRotate(1) {
+		t.Error("Expected WillRotate after midnight")
+	}
+	rw.Write([]byte("day2\n"))
+	now = now.Add(time.Hour)
+	rw.Write([]byte("day2 again\n"))
+
+	if data, _ := os.ReadFile(filename + ".1"); string(data) != "day1\n" {
+		t.Errorf("Expected previous day in .1, got: %q", data)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != "day2\nday2 again\n" {
+		t.Errorf("Expected current day in the current file, got: %q", data)
+	}
+}
+
+func TestRotatingWriter_ScheduleSkipsEmptyPeriods(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	now := time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC)
+	rw := scheduledWriter(t, filename, 0, &now)
+	defer rw.Close()
+
+	// 空文件不轮转，也不产生空的轮转文件
+	now = now.AddDate(0, 0, 3)
+	rw.Write([]byte("first\n"))
+	now = now.Add(time.Hour)
+	rw.Write([]byte("second\n"))
+
+	if _, err := os.Stat(filename + ".1"); !os.IsNotExist(err) {
+		t.Errorf("Expected no rotated file, got: %v", err)
+	}
+}
+
+func TestRotatingWriter_ScheduleWithSize(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	now := time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC)
+	rw := scheduledWriter(t, filename, 10, &now)
+	defer rw.Close()
+
+	rw.Write([]byte("12345678\n"))
+	rw.Write([]byte("abcdefgh\n")) // 超过大小限制
+	now = now.AddDate(0, 0, 1)
+	rw.Write([]byte("next day\n")) // 到达轮转时刻
+
+	for name, want := range map[string]string{".2": "12345678\n", ".1": "abcdefgh\n", "": "next day\n"} {
+		if data, _ := os.ReadFile(filename + name); string(data) != want {
+			t.Errorf("Expected %q in app.log%s, got: %q", want, name, data)
+		}
+	}
+}
+
+func TestRotatingWriter_ScheduleRestart(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(filename, []byte("yesterday\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2025, 3, 4, 18, 0, 0, 0, time.UTC)
+	os.Chtimes(filename, modTime, modTime)
+
+	// 进程在第二天重启，第一次写入时轮转前一天的文件
+	now := time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC)
+	rw := scheduledWriter(t, filename, 0, &now)
+	defer rw.Close()
+	rw.Write([]byte("today\n"))
+
+	if data, _ := os.ReadFile(filename + ".1"); string(data) != "yesterday\n" {
+		t.Errorf("Expected previous day in .1, got: %q", data)
+	}
+}
+
+func TestLoadConfigFromEnv_Rotate(t *testing.T) {
+	os.Setenv("YGGGO_LOG_ROTATE", " daily ")
+	defer os.Unsetenv("YGGGO_LOG_ROTATE")
+
+	if config := LoadConfigFromEnv(); config.Rotate != "daily" {
+		t.Errorf("Expected rotate daily, got: %q", config.Rotate)
+	}
+}
+
+func TestNewLoggerFromConfig_Rotate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := LoadConfigFromEnv()
+	config.OutputFile = filename
+	config.Rotate = "hourly"
+	logger := NewLoggerFromConfig(config)
+
+	combined, ok := logger.formatter.(*CombinedFormatter)
+	if !ok {
+		t.Fatalf("Expected CombinedFormatter, got: %T", logger.formatter)
+	}
+	rw, ok := combined.file.(*RotatingWriter)
+	if !ok || rw.schedule == nil {
+		t.Fatalf("Expected a scheduled RotatingWriter, got: %#v", combined.file)
+	}
+	if next := rw.nextRotation; next.Minute() != 0 || next.Sub(time.Now()) > time.Hour {
+		t.Errorf("Unexpected next rotation: %v", next)
+	}
+	if !strings.HasSuffix(rw.filename, "app.log") {
+		t.Errorf("Unexpected filename: %s", rw.filename)
+	}
+}
+
+func TestNewLoggerFromConfig_InvalidRotate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	config := LoadConfigFromEnv()
+	config.OutputFile = filename
+	config.Rotate = "dayly"
+	logger := NewLoggerFromConfig(config)
+	logger.Close()
+
+	data, _ := os.ReadFile(filename)
+	if !strings.Contains(string(data), "ignored invalid logging configuration") || !strings.Contains(string(data), "YGGGO_LOG_ROTATE") {
+		t.Errorf("Expected the invalid schedule to be reported, got: %q", data)
+	}
+	if len(config.Errors) != 0 {
+		t.Errorf("Expected the caller's config to be left unchanged, got: %v", config.Errors)
+	}
+}
@@ -2,6 +2,7 @@ package ygggo_log
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -9,19 +10,33 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
-// RotatingWriter performs size- and count-based log rotation compatible with io.Writer.
+// RotatingWriter performs size-, time- and count-based log rotation compatible with io.Writer.
 // The current file is <filename>. When rotation occurs, files are renamed as
 // <filename>.1, <filename>.2, ... up to maxFiles-1. When exceeding, oldest are removed.
-// Note: On Windows, files are closed after each write to avoid file-lock issues in tests.
+// With SetCompression, rotated files are gzipped in the background to <filename>.N.gz,
+// and SetRetention additionally removes rotated files by age and total size.
+// Size rotation happens before a write would exceed maxSize; time rotation, enabled
+// with SetSchedule, happens on the first write after each scheduled time.
+// The current file stays open between writes; call Sync to flush it to stable storage
+// and Close to release it (on Windows an open file cannot be removed).
 type RotatingWriter struct {
-	filename    string     // base log filename (current file)
-	maxSize     int64      // max file size in bytes for rotation
-	maxFiles    int        // max number of files including current
-	currentSize int64      // current file size in bytes
-	file        *os.File   // current file handle
-	mutex       sync.Mutex // concurrency protection
+	filename     string           // base log filename (current file)
+	maxSize      int64            // max file size in bytes for rotation
+	maxFiles     int              // max number of files including current
+	currentSize  int64            // current file size in bytes
+	file         *os.File         // current file handle
+	schedule     Schedule         // time-based rotation schedule; nil disables it
+	nextRotation time.Time        // next scheduled rotation; zero when none
+	now          func() time.Time // clock used for scheduling
+	compression  int              // gzip level for rotated files; 0 disables compression
+	retention    Retention        // age and total size limits for rotated files
+	createDir    bool             // create the parent directory when opening the file
+	compressing  sync.WaitGroup   // background compression in progress
+	compressJobs []*compressJob   // rotated files waiting for or under compression
+	mutex        sync.Mutex       // concurrency protection
 }
 
 // NewRotatingWriter creates a RotatingWriter bound to the given filename, with
@@ -31,6 +46,7 @@ func NewRotatingWriter(filename string, maxSize int64, maxFiles int) (*RotatingW
 		filename: filename,
 		maxSize:  maxSize,
 		maxFiles: maxFiles,
+		now:      time.Now,
 	}
 	if err := rw.openFile(); err != nil {
 		return nil, err
@@ -38,22 +54,37 @@ func NewRotatingWriter(filename string, maxSize int64, maxFiles int) (*RotatingW
 	return rw, nil
 }
 
-// openFile 打开或创建日志文件
-func (rw *RotatingWriter) openFile() error {
-	// 获取文件信息
-	if stat, err := os.Stat(rw.filename); err == nil {
-		rw.currentSize = stat.Size()
-	} else {
-		rw.currentSize = 0
+// newDeferredRotatingWriter 创建在首次写入时才创建目录和文件的 RotatingWriter，
+// 用于约定的默认日志路径，避免仅导入包就生成空的日志文件
+func newDeferredRotatingWriter(filename string, maxSize int64, maxFiles int) *RotatingWriter {
+	return &RotatingWriter{
+		filename:  filename,
+		maxSize:   maxSize,
+		maxFiles:  maxFiles,
+		now:       time.Now,
+		createDir: true,
 	}
+}
 
-	// 打开文件
+// openFile 打开或创建日志文件，并以文件当前大小作为已写入的字节数
+func (rw *RotatingWriter) openFile() error {
+	if rw.createDir {
+		if err := os.MkdirAll(filepath.Dir(rw.filename), 0755); err != nil {
+			return err
+		}
+	}
 	file, err := os.OpenFile(rw.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
 		return err
 	}
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
 
 	rw.file = file
+	rw.currentSize = stat.Size()
 	return nil
 }
 
@@ -63,31 +94,81 @@ func (rw *RotatingWriter) Write(p []byte) (n int, err error) {
 	defer rw.mutex.Unlock()
 
 	// 检查是否需要轮转
-	if rw.maxSize > 0 && rw.currentSize+int64(len(p)) > rw.maxSize {
+	if rw.due(len(p)) {
 		if err = rw.rotate(); err != nil {
 			return 0, err
 		}
 	}
+	if rw.schedule != nil && !rw.nextRotation.IsZero() && !rw.now().Before(rw.nextRotation) {
+		// 当前文件为空时不轮转，只推进下一个轮转时刻
+		rw.nextRotation = rw.schedule.Next(rw.now())
+	}
 
-	// 如果文件未打开（例如上一次写入后关闭了），则重新打开
+	// 如果文件未打开（例如已调用 Close），则重新打开
 	if rw.file == nil {
 		if err = rw.openFile(); err != nil {
 			return 0, err
 		}
 	}
 
-	// 写入数据
+	// 写入数据，文件保持打开供下次写入
 	n, err = rw.file.Write(p)
-	if err != nil {
-		return n, err
+	rw.currentSize += int64(n)
+	return n, err
+}
+
+// Sync commits the current file's contents to stable storage.
+func (rw *RotatingWriter) Sync() error {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	if rw.file == nil {
+		return nil
 	}
+	return rw.file.Sync()
+}
 
-	// 写入完成后立即关闭文件，避免长时间占用句柄（兼容测试在 Windows 上的清理）
-	_ = rw.file.Close()
-	rw.file = nil
+// WillRotate reports whether writing n bytes now would start a new file.
+// Formatters that keep per-file state, such as BinaryFormatter, use it to
+// restart that state in the first record of each file.
+func (rw *RotatingWriter) WillRotate(n int) bool {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	return rw.due(n)
+}
 
-	rw.currentSize += int64(n)
-	return n, nil
+// SetSchedule enables time-based rotation, alone or together with the size
+// limit (pass maxSize 0 to NewRotatingWriter to rotate on time only). An
+// existing non-empty file is rotated on the first write if a scheduled time has
+// passed since it was last modified, so a restarted process still starts a new
+// file for each period. Passing nil disables time-based rotation.
+func (rw *RotatingWriter) SetSchedule(schedule Schedule) {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	rw.schedule = schedule
+	rw.nextRotation = time.Time{}
+	if schedule == nil {
+		return
+	}
+	from := rw.now()
+	if stat, err := os.Stat(rw.filename); err == nil && stat.Size() > 0 {
+		from = stat.ModTime()
+	}
+	rw.nextRotation = schedule.Next(from)
+}
+
+// due 判断写入 n 字节前是否需要轮转：超过大小限制，或已到轮转时刻且当前文件不为空
+func (rw *RotatingWriter) due(n int) bool {
+	if rw.maxSize > 0 && rw.currentSize+int64(n) > rw.maxSize {
+		return true
+	}
+	return rw.currentSize > 0 && rw.schedule != nil && !rw.nextRotation.IsZero() && !rw.now().Before(rw.nextRotation)
+}
+
+// Size returns the number of bytes in the current file.
+func (rw *RotatingWriter) Size() int64 {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	return rw.currentSize
 }
 
 // rotate 执行文件轮转
@@ -95,6 +176,7 @@ func (rw *RotatingWriter) rotate() error {
 	// 关闭当前文件
 	if rw.file != nil {
 		_ = rw.file.Close()
+		rw.file = nil
 	}
 
 	// 轮转文件
@@ -102,6 +184,9 @@ func (rw *RotatingWriter) rotate() error {
 	if err != nil {
 		return err
 	}
+	if rw.schedule != nil {
+		rw.nextRotation = rw.schedule.Next(rw.now())
+	}
 
 	// 重新打开文件
 	return rw.openFile()
@@ -109,7 +194,7 @@ func (rw *RotatingWriter) rotate() error {
 
 // rotateFiles 轮转文件名
 func (rw *RotatingWriter) rotateFiles() error {
-	// 获取现有的轮转文件
+	// 获取现有的轮转文件；正在压缩的文件与其他文件一样移动和删除，压缩任务随之更新
 	rotatedFiles, err := rw.getRotatedFiles()
 	if err != nil {
 		return err
@@ -117,27 +202,36 @@ func (rw *RotatingWriter) rotateFiles() error {
 
 	// 删除超出数量限制的文件（总数不超过 rw.maxFiles，包括当前文件）
 	if rw.maxFiles > 1 && len(rotatedFiles) >= rw.maxFiles-1 {
-		// 保留最新的 (maxFiles - 2) 个轮转文件
-		filesToDelete := len(rotatedFiles) - (rw.maxFiles - 2)
-		for i := 0; i < filesToDelete; i++ {
-			_ = os.Remove(rotatedFiles[i].path)
+		// 保留索引最小（最新）的 (maxFiles - 2) 个轮转文件
+		keep := rw.maxFiles - 2
+		for _, f := range rotatedFiles[keep:] {
+			rw.removeRotated(f)
 		}
-		rotatedFiles = rotatedFiles[filesToDelete:]
+		rotatedFiles = rotatedFiles[:keep]
 	}
 
+	// 按保留时间和总大小删除，当前文件即将成为最新的轮转文件
+	rotatedFiles = rw.applyRetention(rotatedFiles, rw.currentSize)
+
 	// 重命名现有文件（索引+1）
 	for i := len(rotatedFiles) - 1; i >= 0; i-- {
-		oldPath := rotatedFiles[i].path
-		newPath := fmt.Sprintf("%s.%d", rw.filename, rotatedFiles[i].index+1)
-		_ = os.Rename(oldPath, newPath)
+		f := rotatedFiles[i]
+		_ = os.Rename(f.path, rw.rotatedPath(f.index+1, f.ext))
+	}
+	for _, job := range rw.compressJobs {
+		if !job.dropped {
+			job.index++
+		}
 	}
 
 	// 将当前文件重命名为 .1
 	if _, err := os.Stat(rw.filename); err == nil {
-		newPath := fmt.Sprintf("%s.1", rw.filename)
-		if err := os.Rename(rw.filename, newPath); err != nil {
+		if err := os.Rename(rw.filename, rw.rotatedPath(1, "")); err != nil {
 			return err
 		}
+		if rw.compression != 0 {
+			rw.compressAsync(1)
+		}
 	}
 
 	return nil
@@ -145,8 +239,27 @@ func (rw *RotatingWriter) rotateFiles() error {
 
 // rotatedFile 轮转文件信息
 type rotatedFile struct {
-	path  string
-	index int
+	path    string
+	index   int
+	ext     string // 压缩文件为 .gz
+	size    int64
+	modTime time.Time
+}
+
+// rotatedPath 返回索引为 index 的轮转文件路径，ext 为 "" 或 ".gz"
+func (rw *RotatingWriter) rotatedPath(index int, ext string) string {
+	return fmt.Sprintf("%s.%d%s", rw.filename, index, ext)
+}
+
+// removeRotated 删除轮转文件；文件正在等待压缩时同时放弃其压缩结果
+func (rw *RotatingWriter) removeRotated(f rotatedFile) {
+	_ = os.Remove(f.path)
+	if f.ext != "" {
+		return
+	}
+	if job := rw.pendingCompression(f.index); job != nil {
+		job.dropped = true
+	}
 }
 
 // getRotatedFiles 获取现有的轮转文件
@@ -160,7 +273,7 @@ func (rw *RotatingWriter) getRotatedFiles() ([]rotatedFile, error) {
 	}
 
 	var rotatedFiles []rotatedFile
-	pattern := regexp.MustCompile(fmt.Sprintf(`^%s\.(\d+)$`, regexp.QuoteMeta(base)))
+	pattern := regexp.MustCompile(fmt.Sprintf(`^%s\.(\d+)(\.gz)?$`, regexp.QuoteMeta(base)))
 
 	for _, file := range files {
 		if file.IsDir() {
@@ -168,15 +281,23 @@ func (rw *RotatingWriter) getRotatedFiles() ([]rotatedFile, error) {
 		}
 
 		matches := pattern.FindStringSubmatch(file.Name())
-		if len(matches) == 2 {
+		if len(matches) == 3 {
 			index, err := strconv.Atoi(matches[1])
 			if err != nil {
 				continue
 			}
 
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+
 			rotatedFiles = append(rotatedFiles, rotatedFile{
-				path:  filepath.Join(dir, file.Name()),
-				index: index,
+				path:    filepath.Join(dir, file.Name()),
+				index:   index,
+				ext:     matches[2],
+				size:    info.Size(),
+				modTime: info.ModTime(),
 			})
 		}
 	}
@@ -189,52 +310,58 @@ func (rw *RotatingWriter) getRotatedFiles() ([]rotatedFile, error) {
 	return rotatedFiles, nil
 }
 
-// Close 关闭写入器
+// Close 关闭当前文件并等待后台压缩完成；之后的 Write 会重新打开文件
 func (rw *RotatingWriter) Close() error {
 	rw.mutex.Lock()
-	defer rw.mutex.Unlock()
-
+	var err error
 	if rw.file != nil {
-		return rw.file.Close()
+		err = rw.file.Close()
+		rw.file = nil
 	}
-	return nil
+	rw.mutex.Unlock()
+
+	// 压缩任务需要获取锁，因此在释放锁之后等待
+	rw.compressing.Wait()
+	return err
 }
 
-// parseSizeString 解析大小字符串
+// parseSizeString 解析大小字符串，格式无效时返回默认值 100MB
 func parseSizeString(sizeStr string) int64 {
-	sizeStr = strings.TrimSpace(strings.ToUpper(sizeStr))
+	if size, ok := parseSize(sizeStr); ok {
+		return size
+	}
+	return 100 * 1024 * 1024
+}
 
-	// 匹配数字和单位
-	re := regexp.MustCompile(`^(\d+)([KMGT]?B?)$`)
-	matches := re.FindStringSubmatch(sizeStr)
+// sizePattern 匹配数字加可选单位，如 100、64K、2GB
+var sizePattern = regexp.MustCompile(`^(\d+)([KMGT]?B?)$`)
 
-	if len(matches) < 2 {
-		// 尝试解析纯数字
-		if size, err := strconv.ParseInt(sizeStr, 10, 64); err == nil {
-			return size
-		}
-		// 解析失败，返回默认值100MB
-		return 100 * 1024 * 1024
+// parseSize 解析 100M、64KB 这样的大小字符串（不区分大小写），格式无效或溢出时 ok 为 false
+func parseSize(sizeStr string) (size int64, ok bool) {
+	matches := sizePattern.FindStringSubmatch(strings.TrimSpace(strings.ToUpper(sizeStr)))
+	if matches == nil {
+		return 0, false
 	}
-
 	size, err := strconv.ParseInt(matches[1], 10, 64)
 	if err != nil {
-		return 100 * 1024 * 1024 // 默认100MB
-	}
-
-	unit := matches[2]
-	switch unit {
-	case "K", "KB":
-		return size * 1024
-	case "M", "MB":
-		return size * 1024 * 1024
-	case "G", "GB":
-		return size * 1024 * 1024 * 1024
-	case "T", "TB":
-		return size * 1024 * 1024 * 1024 * 1024
-	default:
-		return size // 无单位，按字节处理
+		return 0, false
+	}
+
+	var shift uint
+	switch strings.TrimSuffix(matches[2], "B") {
+	case "K":
+		shift = 10
+	case "M":
+		shift = 20
+	case "G":
+		shift = 30
+	case "T":
+		shift = 40
+	}
+	if size > math.MaxInt64>>shift {
+		return 0, false
 	}
+	return size << shift, true
 }
 
 // parseFileNum 解析文件数量字符串（>=1），否则返回默认3
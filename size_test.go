@@ -1,6 +1,7 @@
 package ygggo_log
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -195,6 +196,32 @@ func TestRotatingWriter_FileNaming(t *testing.T) {
 	}
 }
 
+func TestRotatingWriter_FileCountKeepsNewest(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "count.log")
+	writer, err := NewRotatingWriter(logFile, 6, 3)
+	if err != nil {
+		t.Fatalf("Failed to create rotating writer: %v", err)
+	}
+	defer writer.Close()
+
+	// 每次写入都会触发轮转，超出数量时应删除最旧（索引最大）的文件
+	for i := 1; i <= 5; i++ {
+		writer.Write([]byte(fmt.Sprintf("gen-%d\n", i)))
+	}
+
+	want := map[string]string{"count.log": "gen-5\n", "count.log.1": "gen-4\n", "count.log.2": "gen-3\n"}
+	files, _ := filepath.Glob(logFile + "*")
+	if len(files) != len(want) {
+		t.Fatalf("Expected %d files, got: %v", len(want), files)
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(logFile), name))
+		if err != nil || string(data) != content {
+			t.Errorf("Expected %s to hold %q, got: %q (%v)", name, content, data, err)
+		}
+	}
+}
+
 func TestLoggerWithRotation(t *testing.T) {
 	// 重置单例
 	ResetSingleton()
@@ -254,3 +281,100 @@ func TestParseSizeString(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	valid := map[string]int64{"0": 0, "512": 512, "64kb": 64 << 10, " 2G ": 2 << 30, "1TB": 1 << 40}
+	for input, want := range valid {
+		if got, ok := parseSize(input); !ok || got != want {
+			t.Errorf("parseSize(%q): expected %d, got: %d (ok=%v)", input, want, got, ok)
+		}
+	}
+	for _, input := range []string{"", "2GiB", "2 G", "-1", "1.5G", "99999999999T"} {
+		if got, ok := parseSize(input); ok {
+			t.Errorf("parseSize(%q): expected an error, got: %d", input, got)
+		}
+	}
+}
+
+func TestRotatingWriter_KeepsFileOpen(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "open.log")
+	writer, err := NewRotatingWriter(logFile, 0, 3)
+	if err != nil {
+		t.Fatalf("Failed to create rotating writer: %v", err)
+	}
+
+	writer.Write([]byte("first\n"))
+	file := writer.file
+	writer.Write([]byte("second\n"))
+	if file == nil || writer.file != file {
+		t.Error("Expected the file to stay open between writes")
+	}
+	if err := writer.Sync(); err != nil {
+		t.Errorf("Sync failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if writer.file != nil {
+		t.Error("Expected Close to release the file")
+	}
+
+	// Close 之后再次写入会重新打开文件并追加
+	writer.Write([]byte("third\n"))
+	writer.Close()
+	if data, _ := os.ReadFile(logFile); string(data) != "first\nsecond\nthird\n" {
+		t.Errorf("Unexpected content: %q", data)
+	}
+	if writer.Size() != int64(len("first\nsecond\nthird\n")) {
+		t.Errorf("Unexpected size: %d", writer.Size())
+	}
+}
+
+var benchmarkLine = []byte("2025-01-02 15:04:05 [INFO] request handled user=tom status=200 latency=12.5\n")
+
+func BenchmarkRotatingWriter_Write(b *testing.B) {
+	writer, err := NewRotatingWriter(filepath.Join(b.TempDir(), "bench.log"), 0, 3)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer writer.Close()
+	b.SetBytes(int64(len(benchmarkLine)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		writer.Write(benchmarkLine)
+	}
+}
+
+func BenchmarkRotatingWriter_WriteParallel(b *testing.B) {
+	writer, err := NewRotatingWriter(filepath.Join(b.TempDir(), "bench.log"), 0, 3)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer writer.Close()
+	b.SetBytes(int64(len(benchmarkLine)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			writer.Write(benchmarkLine)
+		}
+	})
+}
+
+// BenchmarkReopenPerWrite 作为对照，模拟每次写入都 stat、打开并关闭文件的旧实现
+func BenchmarkReopenPerWrite(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "bench.log")
+	b.SetBytes(int64(len(benchmarkLine)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = os.Stat(path)
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			b.Fatal(err)
+		}
+		file.Write(benchmarkLine)
+		file.Close()
+	}
+}
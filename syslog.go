@@ -0,0 +1,192 @@
+package ygggo_log
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// SyslogProtocol syslog 消息格式
+type SyslogProtocol int
+
+const (
+	// RFC5424 现行 syslog 协议：<PRI>1 时间 主机 应用 进程号 MSGID [结构化数据] 消息
+	RFC5424 SyslogProtocol = iota
+	// RFC3164 传统 BSD syslog：<PRI>Jan _2 15:04:05 主机 应用[进程号]: 消息 key=value
+	RFC3164
+)
+
+// SyslogFacility syslog 设施
+type SyslogFacility int
+
+const (
+	FacilityKern   SyslogFacility = 0  // 内核
+	FacilityUser   SyslogFacility = 1  // 用户程序（默认）
+	FacilityDaemon SyslogFacility = 3  // 系统守护进程
+	FacilityAuth   SyslogFacility = 4  // 安全/认证
+	FacilityLocal0 SyslogFacility = 16 // 本地使用 0
+	FacilityLocal1 SyslogFacility = 17 // 本地使用 1
+	FacilityLocal2 SyslogFacility = 18 // 本地使用 2
+	FacilityLocal3 SyslogFacility = 19 // 本地使用 3
+	FacilityLocal4 SyslogFacility = 20 // 本地使用 4
+	FacilityLocal5 SyslogFacility = 21 // 本地使用 5
+	FacilityLocal6 SyslogFacility = 22 // 本地使用 6
+	FacilityLocal7 SyslogFacility = 23 // 本地使用 7
+)
+
+// SyslogSDID RFC 5424 结构化数据中日志参数使用的 SD-ID（32473 为文档保留的企业号）
+const SyslogSDID = "fields@32473"
+
+// WithFacility 设置 syslog 设施，默认 FacilityUser
+func WithFacility(facility SyslogFacility) FormatterOption {
+	return func(o *formatterOptions) {
+		o.facility = facility
+		o.facilitySet = true
+	}
+}
+
+// SyslogFormatter 输出 syslog 消息，日志级别映射为 syslog 严重程度。
+// RFC 5424 格式下日志参数和常量字段写入结构化数据 [fields@32473 key="value" ...]；
+// RFC 3164 没有结构化数据，参数以 key=value 附加在消息之后。
+// 应用名通过 WithServiceName 设置，默认取可执行文件名。发送到 syslog 可配合 SyslogWriter 使用。
+type SyslogFormatter struct {
+	protocol SyslogProtocol
+	facility SyslogFacility
+	time     timeEncoder
+	header   []byte // 时间之后的 主机 应用 进程号 部分
+	static   []Field
+}
+
+// NewSyslogFormatter 创建 syslog 格式化器。时间布局由协议决定，只有时区配置生效
+func NewSyslogFormatter(protocol SyslogProtocol, opts ...FormatterOption) *SyslogFormatter {
+	o := newFormatterOptions(opts)
+	facility := FacilityUser
+	if o.facilitySet {
+		facility = o.facility
+	}
+	host := hostname()
+	if host == "" {
+		host = "-"
+	}
+	app := syslogName(o.service(), 48)
+	pid := strconv.Itoa(os.Getpid())
+
+	f := &SyslogFormatter{
+		protocol: protocol,
+		facility: facility,
+		static:   o.staticFields,
+	}
+	if protocol == RFC3164 {
+		f.time = newTimeEncoder(formatterOptions{timeLocation: o.timeLocation}, "Jan _2 15:04:05")
+		f.header = []byte(" " + syslogName(host, 255) + " " + app + "[" + pid + "]: ")
+	} else {
+		f.time = newTimeEncoder(formatterOptions{timeLocation: o.timeLocation}, "2006-01-02T15:04:05.000000Z07:00")
+		f.header = []byte(" " + syslogName(host, 255) + " " + app + " " + pid + " - ")
+	}
+	return f
+}
+
+// Protocol 返回格式化器使用的 syslog 协议
+func (f *SyslogFormatter) Protocol() SyslogProtocol {
+	return f.protocol
+}
+
+// Format 格式化为 syslog 消息
+func (f *SyslogFormatter) Format(writer io.Writer, level LogLevel, message string) {
+	formatMessage(f, writer, level, message)
+}
+
+// FormatEntry 格式化为 syslog 消息
+func (f *SyslogFormatter) FormatEntry(writer io.Writer, e *Entry) {
+	buf := getBuffer()
+	buf.b = append(buf.b, '<')
+	buf.b = strconv.AppendInt(buf.b, int64(int(f.facility)*8+syslogSeverity(e.Level)), 10)
+	buf.b = append(buf.b, '>')
+	if f.protocol == RFC3164 {
+		buf.b = f.time.append(buf.b, e.Time)
+		buf.b = append(buf.b, f.header...)
+		buf.b = append(buf.b, e.Message...)
+		if len(f.static) > 0 {
+			buf.b = append(buf.b, ' ')
+			buf.b = appendTextFields(buf.b, f.static, appendRaw)
+		}
+		if len(e.Fields) > 0 {
+			buf.b = append(buf.b, ' ')
+			buf.b = appendTextFields(buf.b, e.Fields, appendRaw)
+		}
+	} else {
+		buf.b = append(buf.b, '1', ' ')
+		buf.b = f.time.append(buf.b, e.Time)
+		buf.b = append(buf.b, f.header...)
+		buf.b = f.appendStructuredData(buf.b, e.Fields)
+		buf.b = append(buf.b, ' ')
+		buf.b = append(buf.b, e.Message...)
+	}
+	buf.b = append(buf.b, '\n')
+	writer.Write(buf.b)
+	putBuffer(buf)
+}
+
+// appendStructuredData 追加 RFC 5424 结构化数据，没有参数时输出 -
+func (f *SyslogFormatter) appendStructuredData(dst []byte, fields []Field) []byte {
+	if len(f.static) == 0 && len(fields) == 0 {
+		return append(dst, '-')
+	}
+	dst = append(dst, "["+SyslogSDID...)
+	dst = appendSyslogParams(dst, f.static)
+	dst = appendSyslogParams(dst, fields)
+	return append(dst, ']')
+}
+
+// appendSyslogParams 以 key="value" 的形式追加 SD-PARAM，每个参数前都带空格
+func appendSyslogParams(dst []byte, fields []Field) []byte {
+	positional := 0
+	for _, field := range fields {
+		dst = append(dst, ' ')
+		start := len(dst)
+		dst = appendFieldKey(dst, field, &positional, appendSyslogParamName)
+		if len(dst)-start > 32 {
+			dst = dst[:start+32]
+		}
+		dst = append(dst, '=', '"')
+		dst = appendFieldValueFunc(dst, field, appendSyslogParamValue)
+		dst = append(dst, '"')
+	}
+	return dst
+}
+
+// appendSyslogParamName 追加 PARAM-NAME，= 、空格、]、" 和非可打印 ASCII 字符替换为 _
+func appendSyslogParamName(dst []byte, name string) []byte {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// appendSyslogParamValue 追加 PARAM-VALUE，转义 "、\ 和 ]
+func appendSyslogParamValue(dst []byte, value string) []byte {
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '"' || c == '\\' || c == ']' {
+			dst = append(dst, '\\')
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// syslogName 将主机名、应用名规范为可打印 ASCII 并截断到 max 个字符，空值返回 -
+func syslogName(s string, max int) string {
+	if s == "" {
+		return "-"
+	}
+	b := appendSyslogParamName(nil, s)
+	if len(b) > max {
+		b = b[:max]
+	}
+	return string(b)
+}
@@ -0,0 +1,64 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSyslogFormatter_RFC5424(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewSyslogFormatter(RFC5424, WithServiceName("api"), WithFacility(FacilityLocal0), WithUTC(),
+		WithStaticFields(String("env", "prod"))))
+
+	logger.Warning("disk low", String("path", `C:\tmp [x]`), Int("free", 3), "a b")
+
+	host := hostname()
+	expected := fmt.Sprintf(`<132>1 2025-06-07T00:09:10.123456Z %s api %d - [fields@32473 env="prod" path="C:\\tmp [x\]" free="3" arg0="a b"] disk low`+"\n",
+		host, os.Getpid())
+	if buf.String() != expected {
+		t.Errorf("Unexpected RFC 5424 message:\n got: %q\nwant: %q", buf.String(), expected)
+	}
+}
+
+func TestSyslogFormatter_RFC5424WithoutFields(t *testing.T) {
+	var buf bytes.Buffer
+	NewSyslogFormatter(RFC5424, WithServiceName("api")).Format(&buf, DebugLevel, "hello")
+
+	if !strings.HasPrefix(buf.String(), "<15>1 ") || !strings.HasSuffix(buf.String(), " - - hello\n") {
+		t.Errorf("Expected nil structured data, got: %q", buf.String())
+	}
+}
+
+func TestSyslogFormatter_RFC3164(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewSyslogFormatter(RFC3164, WithServiceName("api"), WithUTC()))
+
+	logger.Error("failed", String("user", "tom"))
+
+	expected := fmt.Sprintf("<11>Jun  7 00:09:10 %s api[%d]: failed user=tom\n", hostname(), os.Getpid())
+	if buf.String() != expected {
+		t.Errorf("Unexpected RFC 3164 message:\n got: %q\nwant: %q", buf.String(), expected)
+	}
+}
+
+func TestSyslogParamName(t *testing.T) {
+	got := string(appendSyslogParamName(nil, `a=b c]"d`))
+	if got != "a_b_c__d" {
+		t.Errorf("Expected invalid characters to be replaced, got: %s", got)
+	}
+
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetFormatter(NewSyslogFormatter(RFC5424))
+	logger.Info("x", String(strings.Repeat("k", 40), "v"))
+	if !strings.Contains(buf.String(), " "+strings.Repeat("k", 32)+`="v"`) {
+		t.Errorf("Expected PARAM-NAME to be truncated to 32 characters, got: %s", buf.String())
+	}
+}
@@ -0,0 +1,155 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// syslogSocketPaths are the well-known local syslog sockets, tried in order.
+var syslogSocketPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogWriter sends syslog messages to a local syslog daemon or a remote
+// collector such as rsyslog. Each Write must hold one message, as produced by
+// SyslogFormatter; a trailing newline is stripped and the message is framed
+// for the transport:
+//   - unixgram and udp: one datagram per message
+//   - unix (stream): newline-terminated, with newlines inside the message
+//     written as the two characters \n so that one message stays one line
+//   - tcp: octet-counting framing "LEN MSG" (RFC 6587)
+//
+// When a write fails, the connection is re-dialed and the message is retried
+// once. Writes after Close return ErrWriterClosed. SyslogWriter is safe for
+// concurrent use.
+type SyslogWriter struct {
+	network string     // network used for the current connection
+	address string     // socket path or host:port
+	conn    net.Conn   // current connection; nil after a failed write
+	frame   []byte     // reused framing buffer
+	closed  bool       // set by Close; later writes fail instead of re-dialing
+	mutex   sync.Mutex // serializes writes and guards the fields above
+}
+
+// NewSyslogWriter connects to a syslog daemon. The network is "udp", "tcp"
+// (and their 4/6 variants), "unixgram" or "unix" with a socket path as address.
+// An empty network connects to the local daemon through /dev/log (or
+// /var/run/syslog, /var/run/log), trying a datagram and then a stream socket;
+// an address given with an empty network replaces those paths.
+func NewSyslogWriter(network, address string) (*SyslogWriter, error) {
+	w := &SyslogWriter{network: network, address: address}
+	switch network {
+	case "":
+		if err := w.dialLocal(); err != nil {
+			return nil, err
+		}
+		return w, nil
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unixgram", "unix":
+	default:
+		return nil, fmt.Errorf("syslog: unsupported network %q", network)
+	}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// dialLocal connects to the first reachable local syslog socket.
+func (w *SyslogWriter) dialLocal() error {
+	paths := syslogSocketPaths
+	if w.address != "" {
+		paths = []string{w.address}
+	}
+	for _, path := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				w.network, w.address, w.conn = network, path, conn
+				return nil
+			}
+		}
+	}
+	return errors.New("syslog: no local syslog socket found")
+}
+
+// dial (re)connects using the current network and address.
+func (w *SyslogWriter) dial() error {
+	conn, err := net.Dial(w.network, w.address)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// Write sends one syslog message.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	msg := bytes.TrimRight(p, "\r\n")
+	if len(msg) == 0 {
+		return len(p), nil
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+
+	switch w.network {
+	case "tcp", "tcp4", "tcp6":
+		w.frame = strconv.AppendInt(w.frame[:0], int64(len(msg)), 10)
+		w.frame = append(w.frame, ' ')
+		w.frame = append(w.frame, msg...)
+	case "unix":
+		w.frame = appendEscapedNewlines(w.frame[:0], msg)
+		w.frame = append(w.frame, '\n')
+	default:
+		w.frame = append(w.frame[:0], msg...)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if w.conn == nil {
+			if err := w.dial(); err != nil {
+				return 0, err
+			}
+		}
+		_, err := w.conn.Write(w.frame)
+		if err == nil {
+			return len(p), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+		if attempt > 0 {
+			return 0, err
+		}
+	}
+}
+
+// Close closes the underlying connection. Later writes return ErrWriterClosed.
+func (w *SyslogWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// appendEscapedNewlines appends msg with every newline replaced by the two
+// characters \n, for transports that use a newline as the message delimiter.
+func appendEscapedNewlines(dst, msg []byte) []byte {
+	for {
+		i := bytes.IndexByte(msg, '\n')
+		if i < 0 {
+			return append(dst, msg...)
+		}
+		dst = append(dst, msg[:i]...)
+		dst = append(dst, '\\', 'n')
+		msg = msg[i+1:]
+	}
+}
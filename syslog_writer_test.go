@@ -0,0 +1,217 @@
+package ygggo_log
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogWriter_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer pc.Close()
+
+	w, err := NewSyslogWriter("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Failed to create syslog writer: %v", err)
+	}
+	defer w.Close()
+
+	logger := NewLogger(w)
+	logger.SetFormatter(NewSyslogFormatter(RFC5424, WithServiceName("api")))
+	logger.Info("hello rsyslog")
+
+	buf := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Failed to read datagram: %v", err)
+	}
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<14>1 ") || !strings.HasSuffix(msg, " hello rsyslog") {
+		t.Errorf("Unexpected datagram: %q", msg)
+	}
+}
+
+// readOctetCounted 读取一条 octet-counting 分帧的消息
+func readOctetCounted(r *bufio.Reader) (string, error) {
+	lenStr, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(lenStr, " "))
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	_, err = io.ReadFull(r, buf)
+	return string(buf), err
+}
+
+func TestSyslogWriter_TCPOctetCountingAndReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("TCP not available: %v", err)
+	}
+	defer ln.Close()
+
+	messages := make(chan string, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			msg, err := readOctetCounted(r)
+			if err == nil {
+				messages <- msg
+			}
+			conn.Close() // 每条消息后断开，迫使写入端重连
+		}
+	}()
+
+	w, err := NewSyslogWriter("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to create syslog writer: %v", err)
+	}
+	defer w.Close()
+
+	logger := NewLogger(w)
+	logger.SetFormatter(NewSyslogFormatter(RFC5424))
+	logger.Info("first\nline two")
+
+	select {
+	case msg := <-messages:
+		if !strings.HasSuffix(msg, " first\nline two") {
+			t.Errorf("Unexpected framed message: %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the first message")
+	}
+
+	// 服务端已关闭连接，后续写入应在重连后送达
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		logger.Info("second")
+		select {
+		case msg := <-messages:
+			if !strings.HasSuffix(msg, " second") {
+				t.Errorf("Unexpected message after reconnect: %q", msg)
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	t.Fatal("Expected message to be delivered after reconnect")
+}
+
+func TestSyslogWriter_LocalUnixgram(t *testing.T) {
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log")
+
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skipf("unixgram not available: %v", err)
+	}
+	defer pc.Close()
+
+	w, err := NewSyslogWriter("", path)
+	if err != nil {
+		t.Fatalf("Failed to connect to local socket: %v", err)
+	}
+	defer w.Close()
+	if w.network != "unixgram" {
+		t.Errorf("Expected a datagram connection, got: %s", w.network)
+	}
+
+	w.Write([]byte("<14>Jun  7 00:09:10 host app[1]: hi\n"))
+	buf := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Failed to read datagram: %v", err)
+	}
+	if string(buf[:n]) != "<14>Jun  7 00:09:10 host app[1]: hi" {
+		t.Errorf("Unexpected datagram: %q", buf[:n])
+	}
+}
+
+func TestNewSyslogWriter_Errors(t *testing.T) {
+	if _, err := NewSyslogWriter("sctp", "x"); err == nil {
+		t.Error("Expected an error for an unsupported network")
+	}
+	if _, err := NewSyslogWriter("", filepath.Join(os.TempDir(), "no-such-syslog.sock")); err == nil {
+		t.Error("Expected an error when no local socket is reachable")
+	}
+}
+
+func TestSyslogWriter_UnixStreamEscapesNewlines(t *testing.T) {
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets not available: %v", err)
+	}
+	defer ln.Close()
+
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- line
+		}
+	}()
+
+	w, err := NewSyslogWriter("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to create syslog writer: %v", err)
+	}
+	logger := NewLogger(w)
+	logger.SetFormatter(NewSyslogFormatter(RFC3164))
+	logger.Error("first line\nsecond line")
+	logger.Info("next")
+
+	for _, want := range []string{`first line\nsecond line`, "next"} {
+		select {
+		case line := <-lines:
+			if !strings.HasSuffix(line, " "+want+"\n") {
+				t.Errorf("Expected one line ending with %q, got: %q", want, line)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for syslog line")
+		}
+	}
+
+	w.Close()
+	if _, err := w.Write([]byte("<14>late\n")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed after Close, got: %v", err)
+	}
+}
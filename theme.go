@@ -0,0 +1,267 @@
+package ygggo_log
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Style 终端文本样式，内容为 SGR 参数（如 "1;31" 表示粗体红色），空字符串表示不加样式
+type Style string
+
+// ANSI 返回 16 色调色板中的前景色：0-7 为标准色（黑、红、绿、黄、蓝、紫、青、白），8-15 为对应的亮色
+func ANSI(n uint8) Style {
+	if n >= 8 {
+		return Style(strconv.Itoa(90 + int(n&7)))
+	}
+	return Style(strconv.Itoa(30 + int(n)))
+}
+
+// Color256 返回 256 色调色板中的前景色
+func Color256(n uint8) Style {
+	return Style("38;5;" + strconv.Itoa(int(n)))
+}
+
+// RGB 返回 24 位真彩色前景色
+func RGB(r, g, b uint8) Style {
+	return Style("38;2;" + strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b)))
+}
+
+// Bold 返回加粗的样式
+func (s Style) Bold() Style {
+	return s.with("1")
+}
+
+// Dim 返回变暗的样式
+func (s Style) Dim() Style {
+	return s.with("2")
+}
+
+// with 在样式前追加 SGR 参数
+func (s Style) with(param string) Style {
+	if s == "" {
+		return Style(param)
+	}
+	return Style(param + ";" + string(s))
+}
+
+// Code 返回样式的转义序列，没有样式时返回空字符串
+func (s Style) Code() string {
+	if s == "" {
+		return ""
+	}
+	return "\033[" + string(s) + "m"
+}
+
+// Theme 彩色控制台格式化器使用的配色。ColorFormatter 用级别样式渲染整行的头部，
+// PrettyFormatter 另外使用 Time 和 Caller 渲染时间和调用位置。为空的样式不输出转义序列。
+type Theme struct {
+	Debug, Info, Warning, Error, Panic Style // 各级别的样式
+
+	Time   Style // 时间
+	Caller Style // 调用位置
+	Key    Style // 参数键名
+
+	String Style // 字符串值
+	Int    Style // 整数值
+	Float  Style // 浮点数值
+	Bool   Style // 布尔值
+	Other  Style // 时间、时长、错误等其他值
+}
+
+// 内置配色
+var (
+	// DefaultTheme 默认配色，使用 8 色调色板
+	DefaultTheme = Theme{
+		Debug: ANSI(6), Info: ANSI(2), Warning: ANSI(3), Error: ANSI(1), Panic: ANSI(5),
+		Key:    ANSI(6),
+		String: ANSI(7), Int: ANSI(2), Float: ANSI(5), Bool: ANSI(3), Other: ANSI(4),
+	}
+
+	// VividTheme 256 色配色，级别加粗，时间、调用位置和键名变暗
+	VividTheme = Theme{
+		Debug: Color256(244).Bold(), Info: Color256(42).Bold(), Warning: Color256(214).Bold(),
+		Error: Color256(196).Bold(), Panic: Color256(201).Bold(),
+		Time: Color256(245).Dim(), Caller: Color256(245).Dim(), Key: Color256(81).Dim(),
+		String: Color256(255), Int: Color256(120), Float: Color256(177), Bool: Color256(221), Other: Color256(117),
+	}
+
+	// SolarizedTheme Solarized 真彩色配色
+	SolarizedTheme = Theme{
+		Debug: RGB(0x93, 0xa1, 0xa1), Info: RGB(0x85, 0x99, 0x00).Bold(), Warning: RGB(0xb5, 0x89, 0x00).Bold(),
+		Error: RGB(0xdc, 0x32, 0x2f).Bold(), Panic: RGB(0xd3, 0x36, 0x82).Bold(),
+		Time: RGB(0x58, 0x6e, 0x75), Caller: RGB(0x58, 0x6e, 0x75), Key: RGB(0x26, 0x8b, 0xd2),
+		String: RGB(0x2a, 0xa1, 0x98), Int: RGB(0x6c, 0x71, 0xc4), Float: RGB(0x6c, 0x71, 0xc4),
+		Bool: RGB(0xcb, 0x4b, 0x16), Other: RGB(0x26, 0x8b, 0xd2),
+	}
+
+	// MonoTheme 不使用颜色，只用粗体和暗色区分级别与键名
+	MonoTheme = Theme{
+		Warning: Style("").Bold(), Error: Style("").Bold(), Panic: Style("").Bold(),
+		Time: Style("").Dim(), Caller: Style("").Dim(), Key: Style("").Dim(),
+	}
+
+	// NoColorTheme 不输出任何转义序列
+	NoColorTheme = Theme{}
+)
+
+// ThemeByName 按名称返回内置配色：default、vivid、solarized、mono、none，名称不区分大小写
+func ThemeByName(name string) (Theme, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "default", "":
+		return DefaultTheme, true
+	case "vivid", "256":
+		return VividTheme, true
+	case "solarized", "truecolor":
+		return SolarizedTheme, true
+	case "mono":
+		return MonoTheme, true
+	case "none", "plain":
+		return NoColorTheme, true
+	default:
+		return DefaultTheme, false
+	}
+}
+
+// WithTheme 设置 ColorFormatter 和 PrettyFormatter 的配色，默认为 DefaultTheme
+func WithTheme(theme Theme) FormatterOption {
+	return func(o *formatterOptions) {
+		o.theme = &theme
+	}
+}
+
+// palette 预先生成的转义序列，空字符串表示不加样式
+type palette struct {
+	levels [PanicLevel + 1]string
+	time   string
+	caller string
+	key    string
+	str    string
+	int    string
+	float  string
+	bool   string
+	other  string
+}
+
+// defaultPalette DefaultTheme 对应的转义序列
+var defaultPalette = newPalette(DefaultTheme)
+
+// newPalette 生成配色的转义序列
+func newPalette(t Theme) *palette {
+	p := &palette{
+		time:   t.Time.Code(),
+		caller: t.Caller.Code(),
+		key:    t.Key.Code(),
+		str:    t.String.Code(),
+		int:    t.Int.Code(),
+		float:  t.Float.Code(),
+		bool:   t.Bool.Code(),
+		other:  t.Other.Code(),
+	}
+	p.levels[DebugLevel] = t.Debug.Code()
+	p.levels[InfoLevel] = t.Info.Code()
+	p.levels[WarningLevel] = t.Warning.Code()
+	p.levels[ErrorLevel] = t.Error.Code()
+	p.levels[PanicLevel] = t.Panic.Code()
+	return p
+}
+
+// palette 返回配置的配色
+func (o formatterOptions) palette() *palette {
+	if o.theme == nil {
+		return defaultPalette
+	}
+	return newPalette(*o.theme)
+}
+
+// level 返回级别的转义序列
+func (p *palette) level(level LogLevel) string {
+	if level >= DebugLevel && level <= PanicLevel {
+		return p.levels[level]
+	}
+	return ""
+}
+
+// value 返回参数值的转义序列
+func (p *palette) value(t FieldType) string {
+	switch t {
+	case BoolType:
+		return p.bool
+	case IntType, UintType:
+		return p.int
+	case FloatType:
+		return p.float
+	case StringType:
+		return p.str
+	default:
+		return p.other
+	}
+}
+
+// appendStyled 追加带样式的文本，样式为空时原样追加
+func appendStyled[T string | []byte](dst []byte, code string, s T) []byte {
+	if code == "" {
+		return append(dst, s...)
+	}
+	dst = append(dst, code...)
+	dst = append(dst, s...)
+	return append(dst, ColorReset...)
+}
+
+// appendReset 在使用了样式 code 时追加重置序列
+func appendReset(dst []byte, code string) []byte {
+	if code == "" {
+		return dst
+	}
+	return append(dst, ColorReset...)
+}
+
+// ColorMode 控制台是否输出颜色
+type ColorMode int
+
+const (
+	ColorAuto   ColorMode = iota // 按 NO_COLOR、FORCE_COLOR 和输出是否为终端决定
+	ColorAlways                  // 总是输出颜色
+	ColorNever                   // 从不输出颜色
+)
+
+// ColorEnabled 判断写入 w 的日志是否应带颜色。ColorAuto 时依次检查：NO_COLOR 非空则不使用颜色，
+// FORCE_COLOR 非空且不为 0/false 则使用颜色，否则只在 w 是终端时使用颜色
+func ColorEnabled(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("FORCE_COLOR"); force != "" {
+		return force != "0" && !strings.EqualFold(force, "false")
+	}
+	return isTerminal(w)
+}
+
+// isTerminal 判断 w 是否为终端（字符设备）
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// parseColorMode 解析颜色配置：true/false 等布尔值或 auto，无法识别时为 auto
+func parseColorMode(modeStr string) ColorMode {
+	switch strings.ToLower(strings.TrimSpace(modeStr)) {
+	case "true", "1", "yes", "on", "always":
+		return ColorAlways
+	case "false", "0", "no", "off", "never":
+		return ColorNever
+	default:
+		return ColorAuto
+	}
+}
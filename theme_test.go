@@ -0,0 +1,139 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStyle_Codes(t *testing.T) {
+	tests := []struct {
+		style Style
+		want  string
+	}{
+		{ANSI(1), "\033[31m"},
+		{ANSI(9), "\033[91m"},
+		{Color256(214), "\033[38;5;214m"},
+		{RGB(220, 50, 47), "\033[38;2;220;50;47m"},
+		{ANSI(2).Bold(), "\033[1;32m"},
+		{Color256(81).Dim(), "\033[2;38;5;81m"},
+		{Style("").Bold(), "\033[1m"},
+		{Style(""), ""},
+	}
+	for _, tt := range tests {
+		if got := tt.style.Code(); got != tt.want {
+			t.Errorf("Style(%q).Code() = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestThemeByName(t *testing.T) {
+	for _, name := range []string{"default", "Vivid", "solarized", "mono", "none"} {
+		if _, ok := ThemeByName(name); !ok {
+			t.Errorf("Expected theme %q to exist", name)
+		}
+	}
+	if theme, ok := ThemeByName("unknown"); ok || theme != DefaultTheme {
+		t.Errorf("Expected unknown themes to fall back to DefaultTheme")
+	}
+}
+
+func TestColorFormatter_Themes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+
+	logger.SetFormatter(NewColorFormatter(WithTheme(NoColorTheme)))
+	logger.Info("plain", Int("n", 1))
+	if strings.Contains(buf.String(), "\033[") || !strings.Contains(buf.String(), "[INFO]") {
+		t.Errorf("Expected no escape sequences, got: %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.SetFormatter(NewColorFormatter(WithTheme(VividTheme)))
+	logger.Error("vivid", Int("n", 1))
+	if !strings.HasPrefix(buf.String(), "\033[1;38;5;196m") || !strings.Contains(buf.String(), "\033[2;38;5;81mn\033[0m=") {
+		t.Errorf("Expected 256-color bold level and dim key, got: %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.SetFormatter(NewPrettyFormatter(WithTheme(SolarizedTheme)))
+	logger.Warning("solarized")
+	if !strings.Contains(buf.String(), "\033[1;38;2;181;137;0mWARNING") {
+		t.Errorf("Expected a truecolor level, got: %q", buf.String())
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	if ColorEnabled(ColorAuto, &buf) || ColorEnabled(ColorAuto, file) {
+		t.Errorf("Expected no colors for buffers and regular files")
+	}
+	if !ColorEnabled(ColorAlways, &buf) || ColorEnabled(ColorNever, &buf) {
+		t.Errorf("Expected explicit modes to win")
+	}
+
+	t.Setenv("FORCE_COLOR", "1")
+	if !ColorEnabled(ColorAuto, &buf) {
+		t.Errorf("Expected FORCE_COLOR to enable colors")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if ColorEnabled(ColorAuto, &buf) {
+		t.Errorf("Expected NO_COLOR to take precedence over FORCE_COLOR")
+	}
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "0")
+	if ColorEnabled(ColorAuto, &buf) {
+		t.Errorf("Expected FORCE_COLOR=0 not to force colors")
+	}
+}
+
+func TestLoggerFromEnv_ColorAuto(t *testing.T) {
+	t.Setenv("YGGGO_LOG_COLOR", "auto")
+	t.Setenv("YGGGO_LOG_THEME", "mono")
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+
+	config := LoadConfigFromEnv()
+	if config.ColorMode != ColorAuto || config.Color || config.Theme != "mono" {
+		t.Errorf("Unexpected color config: %+v", config)
+	}
+
+	var buf bytes.Buffer
+	if _, ok := NewLoggerFromEnvWithOutput(&buf).formatter.(*TextFormatter); !ok {
+		t.Errorf("Expected plain text when the output is not a terminal")
+	}
+
+	t.Setenv("FORCE_COLOR", "true")
+	logger := NewLoggerFromEnvWithOutput(&buf)
+	logger.Warning("forced")
+	if !strings.HasPrefix(buf.String(), "\033[1m") {
+		t.Errorf("Expected the mono theme to be used, got: %q", buf.String())
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidTheme(t *testing.T) {
+	t.Setenv("YGGGO_LOG_THEME", "solarised")
+
+	config := LoadConfigFromEnv()
+	if config.Theme != "" {
+		t.Errorf("Expected an unknown theme to be dropped, got: %q", config.Theme)
+	}
+	if len(config.Errors) != 1 || !strings.Contains(config.Errors[0].Error(), "YGGGO_LOG_THEME") {
+		t.Errorf("Expected a config error, got: %v", config.Errors)
+	}
+
+	t.Setenv("YGGGO_LOG_THEME", " Vivid ")
+	if config := LoadConfigFromEnv(); len(config.Errors) != 0 {
+		t.Errorf("Expected no config errors for a known theme, got: %v", config.Errors)
+	}
+}
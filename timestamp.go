@@ -0,0 +1,192 @@
+package ygggo_log
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimePrecision 时间戳的小数秒精度
+type TimePrecision int
+
+const (
+	PrecisionDefault TimePrecision = iota // 使用时间布局自身的精度
+	PrecisionSecond                       // 秒
+	PrecisionMilli                        // 毫秒
+	PrecisionMicro                        // 微秒
+	PrecisionNano                         // 纳秒
+)
+
+// EpochLayout 作为时间布局时输出 Unix 时间数字，单位由精度决定（默认秒）
+const EpochLayout = "epoch"
+
+// WithTimeLayout 设置时间布局（Go 时间布局），传入 EpochLayout 时输出 Unix 时间数字
+func WithTimeLayout(layout string) FormatterOption {
+	return func(o *formatterOptions) {
+		o.timeLayout = layout
+	}
+}
+
+// WithTimePrecision 设置小数秒精度，会改写时间布局中秒之后的小数部分
+func WithTimePrecision(precision TimePrecision) FormatterOption {
+	return func(o *formatterOptions) {
+		o.timePrecision = precision
+	}
+}
+
+// WithTimeLocation 设置输出时间使用的时区，nil 表示保持记录时间自身的时区（默认本地时区）
+func WithTimeLocation(loc *time.Location) FormatterOption {
+	return func(o *formatterOptions) {
+		o.timeLocation = loc
+	}
+}
+
+// WithUTC 以 UTC 时区输出时间
+func WithUTC() FormatterOption {
+	return WithTimeLocation(time.UTC)
+}
+
+// timeEncoder 按预先计算好的布局输出时间戳
+type timeEncoder struct {
+	layout   string
+	location *time.Location
+	epoch    bool
+	unit     TimePrecision
+}
+
+// newTimeEncoder 根据可选项和格式化器的默认布局构造时间编码器
+func newTimeEncoder(o formatterOptions, defaultLayout string) timeEncoder {
+	layout := o.timeLayout
+	if layout == "" {
+		layout = defaultLayout
+	}
+	if layout == EpochLayout {
+		unit := o.timePrecision
+		if unit == PrecisionDefault {
+			unit = PrecisionSecond
+		}
+		return timeEncoder{epoch: true, unit: unit, location: o.timeLocation}
+	}
+	return timeEncoder{
+		layout:   applyPrecision(layout, o.timePrecision),
+		location: o.timeLocation,
+	}
+}
+
+// append 追加时间戳；epoch 模式输出整数
+func (te timeEncoder) append(dst []byte, t time.Time) []byte {
+	if te.epoch {
+		return strconv.AppendInt(dst, epochValue(t, te.unit), 10)
+	}
+	if te.location != nil {
+		t = t.In(te.location)
+	}
+	return t.AppendFormat(dst, te.layout)
+}
+
+// appendJSON 追加 JSON 形式的时间戳：布局输出为字符串，epoch 输出为数字
+func (te timeEncoder) appendJSON(dst []byte, t time.Time) []byte {
+	if te.epoch {
+		return te.append(dst, t)
+	}
+	dst = append(dst, '"')
+	dst = te.append(dst, t)
+	return append(dst, '"')
+}
+
+// epochValue 按单位返回 Unix 时间
+func epochValue(t time.Time, unit TimePrecision) int64 {
+	switch unit {
+	case PrecisionMilli:
+		return t.UnixMilli()
+	case PrecisionMicro:
+		return t.UnixMicro()
+	case PrecisionNano:
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}
+
+// applyPrecision 将布局中秒（05）之后的小数部分替换为指定精度，布局不含秒时原样返回
+func applyPrecision(layout string, precision TimePrecision) string {
+	if precision == PrecisionDefault {
+		return layout
+	}
+	i := strings.Index(layout, "05")
+	if i < 0 {
+		return layout
+	}
+	end := i + 2
+	rest := end
+	if rest < len(layout) && (layout[rest] == '.' || layout[rest] == ',') {
+		j := rest + 1
+		for j < len(layout) && (layout[j] == '0' || layout[j] == '9') {
+			j++
+		}
+		if j > rest+1 {
+			rest = j
+		}
+	}
+
+	var fraction string
+	switch precision {
+	case PrecisionMilli:
+		fraction = ".000"
+	case PrecisionMicro:
+		fraction = ".000000"
+	case PrecisionNano:
+		fraction = ".000000000"
+	}
+	return layout[:end] + fraction + layout[rest:]
+}
+
+// parseTimeLayout 解析时间布局配置，支持常用名称或直接使用 Go 时间布局
+func parseTimeLayout(layoutStr string) string {
+	switch strings.ToLower(strings.TrimSpace(layoutStr)) {
+	case "":
+		return ""
+	case "rfc3339":
+		return time.RFC3339
+	case "rfc3339nano":
+		return time.RFC3339Nano
+	case "iso8601":
+		return "2006-01-02T15:04:05.000Z07:00"
+	case "datetime":
+		return time.DateTime
+	case "epoch", "unix":
+		return EpochLayout
+	default:
+		return layoutStr
+	}
+}
+
+// parseTimePrecision 解析精度字符串：s、ms、us（µs）、ns，其他值使用布局默认精度
+func parseTimePrecision(precisionStr string) TimePrecision {
+	switch strings.ToLower(strings.TrimSpace(precisionStr)) {
+	case "s", "sec", "second":
+		return PrecisionSecond
+	case "ms", "milli", "millisecond":
+		return PrecisionMilli
+	case "us", "µs", "micro", "microsecond":
+		return PrecisionMicro
+	case "ns", "nano", "nanosecond":
+		return PrecisionNano
+	default:
+		return PrecisionDefault
+	}
+}
+
+// parseTimeLocation 解析时区：UTC、Local 或 IANA 时区名（如 Asia/Shanghai），空值返回 nil，无法识别时返回错误
+func parseTimeLocation(locStr string) (*time.Location, error) {
+	locStr = strings.TrimSpace(locStr)
+	switch strings.ToLower(locStr) {
+	case "":
+		return nil, nil
+	case "utc", "z":
+		return time.UTC, nil
+	case "local":
+		return time.Local, nil
+	}
+	return time.LoadLocation(locStr)
+}
@@ -0,0 +1,155 @@
+package ygggo_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedClock 返回固定时间的时钟，便于断言时间戳
+func fixedClock() time.Time {
+	return time.Date(2025, 6, 7, 8, 9, 10, 123456789, time.FixedZone("CST", 8*3600))
+}
+
+func TestApplyPrecision(t *testing.T) {
+	testCases := []struct {
+		layout    string
+		precision TimePrecision
+		expected  string
+	}{
+		{"2006-01-02 15:04:05", PrecisionMilli, "2006-01-02 15:04:05.000"},
+		{"2006-01-02 15:04:05.000", PrecisionMicro, "2006-01-02 15:04:05.000000"},
+		{"2006-01-02 15:04:05.000", PrecisionSecond, "2006-01-02 15:04:05"},
+		{time.RFC3339, PrecisionNano, "2006-01-02T15:04:05.000000000Z07:00"},
+		{time.RFC3339Nano, PrecisionMilli, "2006-01-02T15:04:05.000Z07:00"},
+		{"15:04", PrecisionMilli, "15:04"},
+		{"2006-01-02 15:04:05", PrecisionDefault, "2006-01-02 15:04:05"},
+	}
+
+	for _, tc := range testCases {
+		if got := applyPrecision(tc.layout, tc.precision); got != tc.expected {
+			t.Errorf("Expected %q for %q/%v, got: %q", tc.expected, tc.layout, tc.precision, got)
+		}
+	}
+}
+
+func TestTextFormatter_TimeOptions(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewTextFormatter(WithUTC(), WithTimePrecision(PrecisionMicro)))
+
+	logger.Info("utc")
+
+	expected := "2025-06-07 00:09:10.123456 [INFO] utc\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestJsonFormatter_EpochTime(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewJsonFormatter(WithTimeLayout(EpochLayout), WithTimePrecision(PrecisionMilli)))
+
+	logger.Info("epoch")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if ts, ok := entry["timestamp"].(float64); !ok || int64(ts) != fixedClock().UnixMilli() {
+		t.Errorf("Expected numeric epoch milliseconds, got: %v", entry["timestamp"])
+	}
+}
+
+func TestColorFormatter_TimeLayout(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetFormatter(NewColorFormatter(WithTimeLayout(time.RFC3339Nano)))
+
+	logger.Info("nano")
+
+	if !strings.Contains(buf.String(), "2025-06-07T08:09:10.123456789+08:00 [INFO]") {
+		t.Errorf("Expected RFC3339Nano timestamp, got: %q", buf.String())
+	}
+}
+
+func TestPatternFormatter_TimeOptions(t *testing.T) {
+	f, err := NewPatternFormatter("%time|%time{15:04}|%msg", WithUTC(), WithTimePrecision(PrecisionMilli))
+	if err != nil {
+		t.Fatalf("Failed to parse pattern: %v", err)
+	}
+	var buf bytes.Buffer
+	f.FormatEntry(&buf, &Entry{Time: fixedClock(), Message: "p"})
+
+	expected := "2025-06-07 00:09:10.123|00:09|p\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got: %q", expected, buf.String())
+	}
+}
+
+func TestLogger_SetClockNilRestoresNow(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+	logger.SetClock(fixedClock)
+	logger.SetClock(nil)
+
+	logger.Info("now")
+
+	if strings.HasPrefix(buf.String(), "2025-06-07") {
+		t.Errorf("Expected current time after resetting clock, got: %q", buf.String())
+	}
+}
+
+func TestLoadConfigFromEnv_TimeSettings(t *testing.T) {
+	os.Setenv("YGGGO_LOG_TIME_FORMAT", "rfc3339")
+	os.Setenv("YGGGO_LOG_TIME_PRECISION", "ms")
+	os.Setenv("YGGGO_LOG_TIMEZONE", "UTC")
+	defer os.Unsetenv("YGGGO_LOG_TIME_FORMAT")
+	defer os.Unsetenv("YGGGO_LOG_TIME_PRECISION")
+	defer os.Unsetenv("YGGGO_LOG_TIMEZONE")
+
+	config := LoadConfigFromEnv()
+	if config.TimeLayout != time.RFC3339 || config.TimePrecision != PrecisionMilli || config.TimeLocation != time.UTC {
+		t.Fatalf("Unexpected time config: %q %v %v", config.TimeLayout, config.TimePrecision, config.TimeLocation)
+	}
+
+	var buf bytes.Buffer
+	logger := NewLoggerFromEnvWithOutput(&buf)
+	logger.SetClock(fixedClock)
+	logger.Info("env time")
+
+	if !strings.HasPrefix(buf.String(), "2025-06-07T00:09:10.123Z [INFO]") {
+		t.Errorf("Expected env timestamp settings to apply, got: %q", buf.String())
+	}
+}
+
+func TestParseTimeLocation(t *testing.T) {
+	if loc, err := parseTimeLocation(""); loc != nil || err != nil {
+		t.Errorf("Expected nil location for empty value, got: %v %v", loc, err)
+	}
+	if loc, err := parseTimeLocation("Local"); loc != time.Local || err != nil {
+		t.Errorf("Expected time.Local for Local, got: %v %v", loc, err)
+	}
+	if loc, err := parseTimeLocation("Not/AZone"); loc != nil || err == nil {
+		t.Errorf("Expected an error for unknown zone, got: %v", loc)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidTimezone(t *testing.T) {
+	t.Setenv("YGGGO_LOG_TIMEZONE", "Asia/Shangai")
+
+	config := LoadConfigFromEnv()
+	if config.TimeLocation != nil {
+		t.Errorf("Expected an unknown zone to keep local time, got: %v", config.TimeLocation)
+	}
+	if len(config.Errors) != 1 || !strings.Contains(config.Errors[0].Error(), "YGGGO_LOG_TIMEZONE") {
+		t.Errorf("Expected a config error, got: %v", config.Errors)
+	}
+}
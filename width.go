@@ -0,0 +1,73 @@
+package ygggo_log
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// displayWidth 返回文本在终端中占用的列数：全角字符占两列，组合字符和控制字符不占列，
+// ANSI 颜色序列和 OSC 超链接序列不计入宽度
+func displayWidth(b []byte) int {
+	width := 0
+	for i := 0; i < len(b); {
+		if b[i] == '\033' && i+1 < len(b) && b[i+1] == '[' {
+			// 跳过 CSI 序列直到结束字母
+			i += 2
+			for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+				i++
+			}
+			i++
+			continue
+		}
+		if b[i] == '\033' && i+1 < len(b) && b[i+1] == ']' {
+			// 跳过 OSC 序列（如超链接）直到 ST 或 BEL
+			i += 2
+			for i < len(b) && b[i] != '\a' && !(b[i] == '\033' && i+1 < len(b) && b[i+1] == '\\') {
+				i++
+			}
+			if i < len(b) && b[i] == '\033' {
+				i++
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		i += size
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth 返回单个字符的显示列数
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || r == 0x7f:
+		return 0
+	case r < 0x300:
+		return 1
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == 0x200b:
+		return 0
+	case isWideRune(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// isWideRune 判断是否为东亚全角字符或宽 emoji
+func isWideRune(r rune) bool {
+	return r >= 0x1100 && r <= 0x115f || // 谚文字母
+		r >= 0x2e80 && r <= 0x303e || // 中日韩部首、标点
+		r >= 0x3041 && r <= 0x33ff || // 假名、注音、中日韩兼容字符
+		r >= 0x3400 && r <= 0x4dbf || // 中日韩统一表意文字扩展 A
+		r >= 0x4e00 && r <= 0x9fff || // 中日韩统一表意文字
+		r >= 0xa000 && r <= 0xa4cf || // 彝文
+		r >= 0xac00 && r <= 0xd7a3 || // 谚文音节
+		r >= 0xf900 && r <= 0xfaff || // 中日韩兼容表意文字
+		r >= 0xfe30 && r <= 0xfe4f || // 中日韩兼容形式
+		r >= 0xff00 && r <= 0xff60 || // 全角 ASCII
+		r >= 0xffe0 && r <= 0xffe6 || // 全角符号
+		r >= 0x1f300 && r <= 0x1f64f || // 杂项符号和表情
+		r >= 0x1f900 && r <= 0x1f9ff || // 补充符号和表情
+		r >= 0x20000 && r <= 0x3fffd // 中日韩统一表意文字扩展 B 及以后
+}